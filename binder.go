@@ -2,17 +2,21 @@ package echo
 
 import (
 	"database/sql"
+	"encoding"
 	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
 	"reflect"
 	"strconv"
 	"strings"
 	"time"
 	"unicode"
 
-	"github.com/admpub/copier"
 	"github.com/admpub/log"
 
+	"github.com/webx-top/echo/encoding/json"
 	"github.com/webx-top/echo/engine"
 	"github.com/webx-top/echo/param"
 	"github.com/webx-top/tagfast"
@@ -24,6 +28,13 @@ type (
 		Bind(interface{}, Context, ...FormDataFilter) error
 		MustBind(interface{}, Context, ...FormDataFilter) error
 	}
+	// JSONDecodeOptions options of the JSON request body decoder
+	JSONDecodeOptions struct {
+		// UseNumber decodes numbers into json.Number instead of float64
+		UseNumber bool
+		// DisallowUnknownFields rejects fields which do not match the destination struct
+		DisallowUnknownFields bool
+	}
 	binder struct {
 		*Echo
 		decoders map[string]func(interface{}, Context, ...FormDataFilter) error
@@ -31,13 +42,21 @@ type (
 )
 
 func NewBinder(e *Echo) Binder {
+	// copied, so `AddDecoder` does not change the decoders of the other binders
+	decoders := make(map[string]func(interface{}, Context, ...FormDataFilter) error, len(DefaultBinderDecoders))
+	for mime, decoder := range DefaultBinderDecoders {
+		decoders[mime] = decoder
+	}
 	return &binder{
 		Echo:     e,
-		decoders: DefaultBinderDecoders,
+		decoders: decoders,
 	}
 }
 
 func (b *binder) MustBind(i interface{}, c Context, filter ...FormDataFilter) error {
+	if err := DecodeRequestBody(c); err != nil {
+		return err
+	}
 	contentType := c.Request().Header().Get(HeaderContentType)
 	contentType = strings.ToLower(strings.TrimSpace(strings.SplitN(contentType, `;`, 2)[0]))
 	if decoder, ok := b.decoders[contentType]; ok {
@@ -61,8 +80,317 @@ func (b *binder) SetDecoders(decoders map[string]func(interface{}, Context, ...F
 	b.decoders = decoders
 }
 
+// AddDecoder registers the decoder of the request bodies of the MIME type,
+// e.g. `application/vnd.myapp+json`. The MIME type is matched case-insensitively.
 func (b *binder) AddDecoder(mime string, decoder func(interface{}, Context, ...FormDataFilter) error) {
-	b.decoders[mime] = decoder
+	b.decoders[strings.ToLower(mime)] = decoder
+}
+
+// DecodeRequestBody replaces the request body with a decoded stream according
+// to the `Content-Encoding` header and the decoders registered on Echo.
+// Unknown encodings are rejected with 415 Unsupported Media Type.
+func DecodeRequestBody(c Context) error {
+	header := c.Request().Header()
+	encoding := strings.ToLower(strings.TrimSpace(header.Get(HeaderContentEncoding)))
+	if len(encoding) == 0 || encoding == `identity` {
+		return nil
+	}
+	decoder := c.Echo().RequestDecoder(encoding)
+	if decoder == nil {
+		return NewHTTPError(http.StatusUnsupportedMediaType, `unsupported Content-Encoding: `+encoding)
+	}
+	original := c.Request().Body()
+	if original == nil {
+		return nil
+	}
+	body, err := decoder(original)
+	if err != nil {
+		original.Close()
+		return NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	decoded := &decodedBody{Reader: body, decoder: body, original: original}
+	if limit := c.Echo().MaxRequestBodySize(); limit > 0 {
+		// one byte over the limit lets RequestBody report 413 instead of truncating
+		decoded.Reader = io.LimitReader(body, limit+1)
+	}
+	c.Request().SetBody(decoded)
+	header.Del(HeaderContentEncoding)
+	return nil
+}
+
+// decodedBody closes both the decoder and the original request body.
+type decodedBody struct {
+	io.Reader
+	decoder  io.Reader
+	original io.ReadCloser
+}
+
+func (d *decodedBody) Close() error {
+	if closer, ok := d.decoder.(io.Closer); ok {
+		closer.Close()
+	}
+	return d.original.Close()
+}
+
+// FieldSet is a set of field names.
+type FieldSet map[string]struct{}
+
+// Has reports whether the field name is in the set.
+func (f FieldSet) Has(name string) bool {
+	_, ok := f[name]
+	return ok
+}
+
+// BindPartial binds the JSON request body into `i` and returns the top-level
+// keys present in the body, e.g. `{"name":""}` reports `name` but not `age`.
+func BindPartial(i interface{}, c Context, filter ...FormDataFilter) (FieldSet, error) {
+	contentType := c.Request().Header().Get(HeaderContentType)
+	contentType = strings.ToLower(strings.TrimSpace(strings.SplitN(contentType, `;`, 2)[0]))
+	if contentType != MIMEApplicationJSON {
+		return nil, ErrUnsupportedMediaType
+	}
+	body := c.RequestBody()
+	b, err := ioutil.ReadAll(body)
+	body.Close()
+	if err != nil {
+		if he, ok := err.(*HTTPError); ok {
+			return nil, he
+		}
+		return nil, NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	var raw map[string]interface{}
+	if err = json.Unmarshal(b, &raw); err != nil {
+		return nil, NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	fields := make(FieldSet, len(raw))
+	for key := range raw {
+		fields[key] = struct{}{}
+	}
+	// the body is cached by RequestBody, so it is read again by MustBind
+	if err = c.MustBind(i, filter...); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// BindParams binds the path params into the struct fields with a `param` tag,
+// e.g. `param:"id"`. A value which can not be converted to the field type is
+// reported as 400 Bad Request. Embedded structs are bound as well.
+func BindParams(i interface{}, c Context) error {
+	vc := reflect.ValueOf(i)
+	if vc.Kind() != reflect.Ptr || vc.Elem().Kind() != reflect.Struct {
+		return errors.New(`binder: BindParams requires a pointer to struct`)
+	}
+	names := c.ParamNames()
+	values := c.ParamValues()
+	params := make(map[string]string, len(names))
+	for index, name := range names {
+		if index < len(values) {
+			params[name] = values[index]
+		}
+	}
+	_, err := bindTaggedFields(vc.Elem(), `param`, func(name string) (string, bool) {
+		v, ok := params[name]
+		return v, ok
+	})
+	return err
+}
+
+// BindHeaders binds the request headers into the struct fields with a `header`
+// tag, e.g. `header:"X-Token"`. Embedded structs are bound as well.
+func BindHeaders(i interface{}, c Context) error {
+	vc := reflect.ValueOf(i)
+	if vc.Kind() != reflect.Ptr || vc.Elem().Kind() != reflect.Struct {
+		return errors.New(`binder: BindHeaders requires a pointer to struct`)
+	}
+	header := c.Request().Header()
+	_, err := bindTaggedFields(vc.Elem(), `header`, func(name string) (string, bool) {
+		v := header.Get(name)
+		return v, len(v) > 0
+	})
+	return err
+}
+
+// BindQuery binds the query parameters into the struct fields tagged with
+// `query`, e.g. query:"page". Embedded structs are bound as well. A slice of
+// structs is bound from the bracketed indices, e.g. `filters[0][field]=name`
+// for the field tagged query:"filters" whose elements have a field tagged
+// query:"field".
+func BindQuery(i interface{}, c Context) error {
+	vc := reflect.ValueOf(i)
+	if vc.Kind() != reflect.Ptr || vc.Elem().Kind() != reflect.Struct {
+		return errors.New(`binder: BindQuery requires a pointer to struct`)
+	}
+	_, err := bindTaggedFields(vc.Elem(), `query`, func(name string) (string, bool) {
+		v := c.Query(name)
+		return v, len(v) > 0
+	})
+	return err
+}
+
+// bindTaggedFields sets the fields of the struct tagged with tag to the values
+// returned by lookup, descending into embedded structs. A nil embedded struct
+// pointer is only allocated if one of its fields is bound or gets a default.
+// The fields which are absent from the source and still zero get the value of
+// their `default` tag, e.g. query:"size" default:"10".
+func bindTaggedFields(vc reflect.Value, tag string, lookup func(string) (string, bool)) (bound bool, err error) {
+	tc := vc.Type()
+	for index := 0; index < tc.NumField(); index++ {
+		f := tc.Field(index)
+		fv := vc.Field(index)
+		name := f.Tag.Get(tag)
+		if f.Anonymous && len(name) == 0 {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() != reflect.Struct {
+				continue
+			}
+			var ok bool
+			if fv.Kind() == reflect.Ptr && fv.IsNil() {
+				if !fv.CanSet() {
+					continue
+				}
+				nv := reflect.New(ft)
+				if ok, err = bindTaggedFields(nv.Elem(), tag, lookup); ok || !isZeroValue(nv.Elem()) {
+					fv.Set(nv)
+				}
+			} else {
+				ok, err = bindTaggedFields(reflect.Indirect(fv), tag, lookup)
+			}
+			if err != nil {
+				return
+			}
+			bound = bound || ok
+			continue
+		}
+		if len(name) == 0 || name == `-` || !fv.CanSet() {
+			continue
+		}
+		if et, ok := structSliceElem(f.Type); ok {
+			var n bool
+			if n, err = bindStructSlice(fv, et, tag, name, lookup); err != nil {
+				return
+			}
+			bound = bound || n
+			continue
+		}
+		v, ok := lookup(name)
+		if !ok {
+			if def, has := f.Tag.Lookup(`default`); has && isZeroValue(fv) {
+				if err = setParamValue(fv, def); err != nil {
+					return bound, fmt.Errorf(`binder: invalid default of field %s: %v`, f.Name, err)
+				}
+			}
+			continue
+		}
+		if err = setParamValue(fv, v); err != nil {
+			return bound, NewHTTPError(http.StatusBadRequest, fmt.Sprintf(`invalid %s %s: %v`, tag, name, err))
+		}
+		bound = true
+	}
+	return
+}
+
+var (
+	fromConversionType  = reflect.TypeOf((*FromConversion)(nil)).Elem()
+	bindUnmarshalerType = reflect.TypeOf((*BindUnmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// structSliceElem returns the struct type of the elements of the slice type t,
+// unless they decode themselves from a single value, e.g. time.Time.
+func structSliceElem(t reflect.Type) (reflect.Type, bool) {
+	if t.Kind() != reflect.Slice {
+		return nil, false
+	}
+	et := t.Elem()
+	if et.Kind() == reflect.Ptr {
+		et = et.Elem()
+	}
+	if et.Kind() != reflect.Struct {
+		return nil, false
+	}
+	pt := reflect.PtrTo(et)
+	if pt.Implements(fromConversionType) || pt.Implements(bindUnmarshalerType) || pt.Implements(textUnmarshalerType) {
+		return nil, false
+	}
+	return et, true
+}
+
+// bindStructSlice sets the slice fv to the structs looked up as
+// `name[0][field]`, `name[1][field]`... until an index binds no field.
+func bindStructSlice(fv reflect.Value, et reflect.Type, tag string, name string, lookup func(string) (string, bool)) (bound bool, err error) {
+	for index := 0; ; index++ {
+		prefix := name + `[` + strconv.Itoa(index) + `]`
+		ev := reflect.New(et)
+		var ok bool
+		ok, err = bindTaggedFields(ev.Elem(), tag, func(field string) (string, bool) {
+			return lookup(prefix + `[` + field + `]`)
+		})
+		if err != nil || !ok {
+			return
+		}
+		if !bound {
+			fv.Set(reflect.MakeSlice(fv.Type(), 0, 1))
+			bound = true
+		}
+		if fv.Type().Elem().Kind() != reflect.Ptr {
+			ev = ev.Elem()
+		}
+		fv.Set(reflect.Append(fv, ev))
+	}
+}
+
+func isZeroValue(v reflect.Value) bool {
+	return reflect.DeepEqual(v.Interface(), reflect.Zero(v.Type()).Interface())
+}
+
+func setParamValue(tv reflect.Value, v string) error {
+	if tv.Kind() == reflect.Ptr {
+		if tv.IsNil() {
+			tv.Set(reflect.New(tv.Type().Elem()))
+		}
+		tv = tv.Elem()
+	}
+	if conv, ok := tv.Addr().Interface().(FromConversion); ok {
+		return conv.FromString(v)
+	}
+	if ok, err := unmarshalField(tv, v); ok {
+		return err
+	}
+	switch tv.Kind() {
+	case reflect.String:
+		tv.SetString(v)
+	case reflect.Bool:
+		x, err := strconv.ParseBool(v)
+		if err != nil {
+			return err
+		}
+		tv.SetBool(x)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		x, err := strconv.ParseInt(v, 10, tv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		tv.SetInt(x)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		x, err := strconv.ParseUint(v, 10, tv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		tv.SetUint(x)
+	case reflect.Float32, reflect.Float64:
+		x, err := strconv.ParseFloat(v, tv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		tv.SetFloat(x)
+	default:
+		return fmt.Errorf(`unsupported type %v`, tv.Type())
+	}
+	return nil
 }
 
 // FormNames user[name][test]
@@ -290,26 +618,43 @@ var (
 	ErrExit               = errors.New("[EXIT]")
 	ErrReturn             = errors.New("[RETURN]")
 	ErrSliceIndexTooLarge = errors.New("The slice index value of the form field is too large")
+	ErrSliceTooLong       = errors.New("The number of values of the form field is too large")
 )
 
-func SafeGetFieldByName(parentT reflect.Type, parentV reflect.Value, name string, value reflect.Value) (v reflect.Value) {
-	defer func() {
-		if r := recover(); r != nil {
-			switch fmt.Sprint(r) {
-			case `reflect: indirection through nil pointer to embedded struct`:
-				copier.InitNilFields(parentT, parentV, ``, copier.AllNilFields)
-				v = value.FieldByName(name)
-			default:
-				panic(r)
+// SafeGetFieldByName returns the field of value named name, see `GetFieldByName`.
+//
+// Deprecated: parentT and parentV are not used, use GetFieldByName instead.
+func SafeGetFieldByName(parentT reflect.Type, parentV reflect.Value, name string, value reflect.Value) reflect.Value {
+	return GetFieldByName(value, name)
+}
+
+// GetFieldByName returns the field of value named name, allocating the nil
+// embedded struct pointers on the way to a promoted field. The returned value
+// is invalid if a nil embedded pointer can not be set, i.e. its type is
+// unexported.
+func GetFieldByName(value reflect.Value, name string) (v reflect.Value) {
+	f, ok := value.Type().FieldByName(name)
+	if !ok {
+		return
+	}
+	v = value
+	for i, x := range f.Index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return reflect.Value{}
+				}
+				v.Set(reflect.New(v.Type().Elem()))
 			}
+			v = v.Elem()
 		}
-	}()
-	v = value.FieldByName(name)
+		v = v.Field(x)
+	}
 	return
 }
 
 func setField(e *Echo, parentT reflect.Type, parentV reflect.Value, k string, name string, value reflect.Value, typev reflect.Type, values []string) error {
-	tv := SafeGetFieldByName(parentT, parentV, name, value)
+	tv := GetFieldByName(value, name)
 	if !tv.IsValid() {
 		return ErrBreak
 	}
@@ -327,6 +672,14 @@ func setField(e *Echo, parentT reflect.Type, parentV reflect.Value, k string, na
 	}
 	v := values[0]
 	var l interface{}
+	if _, ok := tv.Addr().Interface().(FromConversion); !ok && tv.Type() != timeType {
+		if ok, err := unmarshalField(tv, v); ok {
+			if err != nil {
+				e.Logger().Warnf(`binder: arg %v as %v: %v`, v, tv.Type(), err)
+			}
+			return validateField(e, parentT, f, name, v)
+		}
+	}
 	switch kind := tv.Kind(); kind {
 	case reflect.String:
 		switch tagfast.Value(parentT, f, `form_filter`) {
@@ -457,12 +810,18 @@ func setField(e *Echo, parentT reflect.Type, parentV reflect.Value, k string, na
 	case reflect.Ptr:
 		e.Logger().Warn(`binder: can not set an ptr of ptr`)
 	case reflect.Slice, reflect.Array:
+		if e.FormSliceMaxLength > 0 && len(values) > e.FormSliceMaxLength {
+			return fmt.Errorf(`%w, greater than %d`, ErrSliceTooLong, e.FormSliceMaxLength)
+		}
 		setSlice(e, name, tv, values)
 	default:
 		return ErrBreak
 	}
 
-	//validation
+	return validateField(e, parentT, f, name, l)
+}
+
+func validateField(e *Echo, parentT reflect.Type, f reflect.StructField, name string, l interface{}) error {
 	valid := tagfast.Value(parentT, f, `valid`)
 	if len(valid) == 0 {
 		return nil
@@ -526,6 +885,29 @@ type FromConversion interface {
 	FromString(content string) error
 }
 
+// BindUnmarshaler is implemented by the field types which decode themselves
+// from the string value of a param, query, form or header field.
+type BindUnmarshaler interface {
+	UnmarshalParam(param string) error
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// unmarshalField decodes v into tv if its type implements BindUnmarshaler or
+// encoding.TextUnmarshaler, it reports whether one of them was used.
+func unmarshalField(tv reflect.Value, v string) (bool, error) {
+	if !tv.CanAddr() {
+		return false, nil
+	}
+	switch u := tv.Addr().Interface().(type) {
+	case BindUnmarshaler:
+		return true, u.UnmarshalParam(v)
+	case encoding.TextUnmarshaler:
+		return true, u.UnmarshalText([]byte(v))
+	}
+	return false, nil
+}
+
 // ToConversion a struct implements this interface can be convert from struct to template variable
 // Not Implemented
 type ToConversion interface {
@@ -2,6 +2,8 @@ package echo
 
 import (
 	"database/sql"
+	"errors"
+	"strings"
 	"testing"
 	"time"
 
@@ -229,3 +231,86 @@ func TestMapToMapStruct(t *testing.T) {
 	}, m)
 	//Dump(m)
 }
+
+type TestColor int
+
+const (
+	TestColorUnknown TestColor = iota
+	TestColorRed
+	TestColorGreen
+)
+
+func (c *TestColor) UnmarshalText(b []byte) error {
+	switch string(b) {
+	case `red`:
+		*c = TestColorRed
+	case `green`:
+		*c = TestColorGreen
+	default:
+		return errors.New(`unknown color: ` + string(b))
+	}
+	return nil
+}
+
+type TestUpper string
+
+func (u *TestUpper) UnmarshalParam(param string) error {
+	*u = TestUpper(strings.ToUpper(param))
+	return nil
+}
+
+type TestPaint struct {
+	Color   TestColor
+	Border  *TestColor
+	Label   TestUpper
+	Created time.Time
+}
+
+func TestMapToUnmarshaler(t *testing.T) {
+	e := New()
+	m := &TestPaint{}
+	err := NamedStructMap(e, m, map[string][]string{
+		`color`:   {`green`},
+		`border`:  {`red`},
+		`label`:   {`hello`},
+		`created`: {`2021-02-03`},
+	}, ``)
+	assert.NoError(t, err)
+	assert.Equal(t, TestColorGreen, m.Color)
+	if assert.NotNil(t, m.Border) {
+		assert.Equal(t, TestColorRed, *m.Border)
+	}
+	assert.Equal(t, TestUpper(`HELLO`), m.Label)
+	assert.Equal(t, 2021, m.Created.Year())
+
+	m = &TestPaint{}
+	NamedStructMap(e, m, map[string][]string{
+		`color`: {`blue`},
+	}, ``)
+	assert.Equal(t, TestColorUnknown, m.Color)
+}
+
+type TestTags struct {
+	Tags []string
+	IDs  []int
+}
+
+func TestMapToSliceMaxLength(t *testing.T) {
+	e := New()
+	e.SetFormSliceMaxLength(3)
+	m := &TestTags{}
+	err := NamedStructMap(e, m, map[string][]string{
+		`tags`: {`a`, `b`, `c`},
+		`IDs`:  {`1`, `2`},
+	}, ``)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{`a`, `b`, `c`}, m.Tags)
+	assert.Equal(t, []int{1, 2}, m.IDs)
+
+	m = &TestTags{}
+	err = NamedStructMap(e, m, map[string][]string{
+		`IDs[]`: {`1`, `2`, `3`, `4`},
+	}, ``)
+	assert.True(t, errors.Is(err, ErrSliceTooLong))
+	assert.Nil(t, m.IDs)
+}
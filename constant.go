@@ -48,8 +48,11 @@ const (
 	MIMEApplicationJSONCharsetUTF8       = MIMEApplicationJSON + "; " + CharsetUTF8
 	MIMEApplicationJavaScript            = "application/javascript"
 	MIMEApplicationJavaScriptCharsetUTF8 = MIMEApplicationJavaScript + "; " + CharsetUTF8
+	MIMEApplicationProblemJSON           = "application/problem+json"
+	MIMEApplicationNDJSON                = "application/x-ndjson"
 	MIMEApplicationXML                   = "application/xml"
 	MIMEApplicationXMLCharsetUTF8        = MIMEApplicationXML + "; " + CharsetUTF8
+	MIMETextXML                          = "text/xml"
 	MIMEApplicationForm                  = "application/x-www-form-urlencoded"
 	MIMEApplicationProtobuf              = "application/protobuf"
 	MIMEApplicationMsgpack               = "application/msgpack"
@@ -96,6 +99,9 @@ const (
 	HeaderServer              = "Server"
 	HeaderOrigin              = "Origin"
 	HeaderCacheControl        = "Cache-Control"
+	HeaderRetryAfter          = "Retry-After"
+	HeaderExpect              = "Expect"
+	HeaderConnection          = "Connection"
 
 	// Access control
 	HeaderAccessControlRequestMethod    = "Access-Control-Request-Method"
@@ -53,6 +53,9 @@ type Context interface {
 	//----------------
 
 	Path() string
+	// FullPath is an alias of `Path`, the template of the matched route, e.g.
+	// `/users/:id`, but returns an empty string when no route matched the request.
+	FullPath() string
 	P(int, ...string) string
 	Param(string, ...string) string
 	// ParamNames returns path parameter names.
@@ -71,6 +74,9 @@ type Context interface {
 	QueryValues(string) []string
 	QueryxValues(string) param.StringSlice
 	Query(string, ...string) string
+	// QueryString returns the raw, unparsed query string of the request URI,
+	// e.g. for signature verification. It is an alias for `engine.URL#RawQuery()`.
+	QueryString() string
 
 	//----------------
 	// Form data
@@ -81,6 +87,12 @@ type Context interface {
 	FormxValues(string) param.StringSlice
 	// Forms returns the form parameters as map. It is an alias for `engine.Request#Form().All()`.
 	Forms() map[string][]string
+	// RequestBody returns a new reader of the request body positioned at the start
+	// on each call. The body is decoded according to `Content-Encoding`, read once
+	// and cached, up to `Echo.MaxRequestBodySize`, or `DefaultRequestBodyCacheSize`
+	// when the engine does not limit it, and the `BodyLimit` middleware limit.
+	// An oversized body is reported as `ErrStatusRequestEntityTooLarge`.
+	RequestBody() io.ReadCloser
 
 	// Param+
 	Px(int, ...string) param.String
@@ -96,11 +108,19 @@ type Context interface {
 	// Context data
 	//----------------
 
+	// Set stores a value for the current request. Values stay available
+	// until the context is reset for the next request, so the
+	// `HTTPErrorHandler` can still read them after a handler returns an error.
 	Set(string, interface{})
 	Get(string, ...interface{}) interface{}
 	Delete(...string)
 	Stored() Store
+	// Internal returns the request-scoped store for framework and middleware
+	// data. It is cleared by Reset, so values never outlive the current request.
 	Internal() *param.SafeMap
+	// CSPNonce returns a random nonce for the Content-Security-Policy header.
+	// It is generated on first use and stays the same for the whole request.
+	CSPNonce() string
 
 	//----------------
 	// Bind
@@ -108,6 +128,26 @@ type Context interface {
 
 	Bind(interface{}, ...FormDataFilter) error
 	MustBind(interface{}, ...FormDataFilter) error
+	// BindAndValidate binds and validates the request data, a validation
+	// failure is returned as *ValidateErrors (422).
+	BindAndValidate(interface{}, ...FormDataFilter) error
+	// BindJSON and BindXML decode the request body regardless of the
+	// `Content-Type` header, the body is limited by `Echo.MaxRequestBodySize`.
+	BindJSON(interface{}) error
+	BindXML(interface{}) error
+	// BindParams binds the path params into the struct fields tagged with `param`.
+	BindParams(interface{}) error
+	// BindHeaders binds the request headers into the struct fields tagged with `header`.
+	BindHeaders(interface{}) error
+	// BindQuery binds the query parameters into the struct fields tagged with `query`.
+	BindQuery(interface{}) error
+	// BindNDJSON decodes the newline-delimited JSON records of the request body,
+	// which is limited as by RequestBody and decoded with the JSONDecodeOptions.
+	// fn decodes them one by one with decode, which returns io.EOF after the last one.
+	BindNDJSON(fn func(decode func(interface{}) error) error) error
+	// BindPartial binds the JSON request body and reports the keys present in
+	// it, so a PATCH handler can tell an omitted field from a zero value.
+	BindPartial(interface{}, ...FormDataFilter) (FieldSet, error)
 
 	//----------------
 	// Response data
@@ -118,12 +158,41 @@ type Context interface {
 	String(string, ...int) error
 	Blob([]byte, ...int) error
 	JSON(interface{}, ...int) error
+	// JSONOmitEmpty is like JSON but omits the null and empty values of the
+	// objects recursively.
+	JSONOmitEmpty(interface{}, ...int) error
 	JSONBlob([]byte, ...int) error
 	JSONP(string, interface{}, ...int) error
 	XML(interface{}, ...int) error
 	XMLBlob([]byte, ...int) error
 	Stream(func(io.Writer) bool)
 	SSEvent(string, chan interface{}) error
+
+	// Push initiates an HTTP/2 server push of the target, it returns
+	// `http.ErrNotSupported` if the engine or the connection does not support it.
+	Push(target string, opts *http.PushOptions) error
+
+	// Flush sends any buffered response data to the client.
+	Flush()
+
+	// AddVary appends the header names to the `Vary` response header without duplicates.
+	AddVary(...string)
+
+	// CacheControl merges the directives into the `Cache-Control` header without
+	// duplicates, see `MergeCacheControl`.
+	CacheControl(directives ...string)
+	// NoStore adds the `no-store` directive, it removes `max-age`.
+	NoStore()
+	// MaxAge adds the `max-age` directive in seconds.
+	MaxAge(d time.Duration)
+	// Private adds the `private` directive, it replaces `public`.
+	Private()
+	// Public adds the `public` directive, it replaces `private`.
+	Public()
+
+	// OnWrite registers a callback called with the number of bytes of each
+	// write of the response body, e.g. for download progress or metrics.
+	OnWrite(func(n int))
 	File(string, ...http.FileSystem) error
 	Attachment(io.Reader, string, ...bool) error
 	NoContent(...int) error
@@ -138,7 +207,10 @@ type Context interface {
 
 	// ServeContent sends static content from `io.Reader` and handles caching
 	// via `If-Modified-Since` request header. It automatically sets `Content-Type`
-	// and `Last-Modified` response headers.
+	// and `Last-Modified` response headers. When the content is an `io.ReadSeeker`
+	// it behaves like `http.ServeContent`, also handling `Range`, `If-Range`,
+	// `If-None-Match` and `If-Unmodified-Since`. The errors reading the content
+	// or writing the response are returned.
 	ServeContent(io.Reader, string, time.Time) error
 	ServeCallbackContent(func(Context) (io.Reader, error), string, time.Time) error
 
@@ -146,6 +218,8 @@ type Context interface {
 	// FuncMap
 	//----------------
 
+	// SetFunc adds a template function for the current request only, it is
+	// merged over the `FuncMap` of Echo when rendering.
 	SetFunc(string, interface{})
 	GetFunc(string) interface{}
 	ResetFuncs(map[string]interface{})
@@ -168,6 +242,8 @@ type Context interface {
 	NewCookie(string, string) *Cookie
 	Cookie() Cookier
 	GetCookie(string) string
+	// Cookies returns all cookies of the request, see `Cookier.All`.
+	Cookies() []*http.Cookie
 	// SetCookie @param:key,value,maxAge(seconds),path(/),domain,secure,httpOnly,sameSite(lax/strict/default)
 	SetCookie(string, string, ...interface{})
 
@@ -191,6 +267,8 @@ type Context interface {
 	PjaxContainer() string
 	Method() string
 	Format() string
+	// SetFormat forces the response format of the current request, overriding
+	// the negotiation of `Format`, `Accepts` and the default error handler.
 	SetFormat(string)
 	IsPost() bool
 	IsGet() bool
@@ -206,6 +284,9 @@ type Context interface {
 	WithFormatExtension(bool)
 	ResolveFormat() string
 	Accept() *Accepts
+	// Accepts returns the first of the formats (e.g. `html`, `json`) accepted
+	// by the client according to the `Accept` header, or empty if none.
+	Accepts(...string) string
 	Protocol() string
 	Site() string
 	RequestURI() string
@@ -222,14 +303,34 @@ type Context interface {
 	MapData(i interface{}, data map[string][]string, names ...string) error
 	SaveUploadedFile(fieldName string, saveAbsPath string, saveFileName ...string) (*multipart.FileHeader, error)
 	SaveUploadedFileToWriter(string, io.Writer) (*multipart.FileHeader, error)
+	// BindFile saves the file uploaded in the field to the directory under its
+	// sanitized name and returns the saved path. An existing file is never
+	// overwritten, a counter is appended to the name instead. A file larger
+	// than the options allow is rejected with 413, a file of another type with 415.
+	BindFile(fieldName string, dstDir string, options ...*UploadOptions) (string, error)
 	//Multiple file upload
 	SaveUploadedFiles(fieldName string, savePath func(*multipart.FileHeader) (string, error)) error
 	SaveUploadedFilesToWriter(fieldName string, writer func(*multipart.FileHeader) (io.Writer, error)) error
 
+	//----------------
+	// Sub request
+	//----------------
+
+	// SubRequest dispatches a synthetic request through the router and
+	// middleware of the current Echo instance and returns the captured response.
+	// The request is wrapped by the `standard` engine, which must be imported
+	// when running on another engine, or else `engine.ErrUnsupported` is returned.
+	SubRequest(method string, path string, body io.Reader) (*Response, error)
+
 	//----------------
 	// Hook
 	//----------------
 
 	AddPreResponseHook(func() error) Context
 	SetPreResponseHook(...func() error) Context
+
+	// AddPostResponseHook registers a hook that runs after the response has been
+	// written, including when the handler returned an error or panicked. A panic
+	// of the hook is logged and does not prevent the next hooks from running.
+	AddPostResponseHook(func(Context)) Context
 }
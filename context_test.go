@@ -0,0 +1,1353 @@
+package echo_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/webx-top/echo"
+	"github.com/webx-top/echo/engine"
+	mw "github.com/webx-top/echo/middleware"
+	test "github.com/webx-top/echo/testing"
+)
+
+func TestContextResetClearsRequestScopedData(t *testing.T) {
+	e := New()
+	req, res := test.NewRequestAndResponse(GET, "/")
+	c := e.NewContext(req, res)
+	c.Internal().Set(`user`, `admin`)
+	c.Set(`title`, `home`)
+	assert.Equal(t, `admin`, c.Internal().String(`user`))
+
+	req, res = test.NewRequestAndResponse(GET, "/next")
+	c.Reset(req, res)
+	assert.False(t, c.Internal().Has(`user`))
+	assert.Nil(t, c.Get(`title`))
+}
+
+func TestContextCSPNonce(t *testing.T) {
+	e := New()
+	req, res := test.NewRequestAndResponse(GET, "/")
+	c := e.NewContext(req, res)
+	nonce := c.CSPNonce()
+	assert.NotEmpty(t, nonce)
+	assert.Equal(t, nonce, c.CSPNonce())
+
+	req, res = test.NewRequestAndResponse(GET, "/")
+	c.Reset(req, res)
+	assert.NotEqual(t, nonce, c.CSPNonce())
+}
+
+func TestContextCookies(t *testing.T) {
+	e := New()
+	req := test.NewStdRequest(GET, "/")
+	req.Header.Set(HeaderCookie, `SID=abc; lang=zh-CN; theme=dark%20blue`)
+	c := e.NewContext(test.WrapRequest(req), test.WrapResponse(req, test.NewStdResponse()))
+	cookies := c.Cookies()
+	assert.Len(t, cookies, 3)
+	assert.Equal(t, `SID`, cookies[0].Name)
+	assert.Equal(t, `abc`, cookies[0].Value)
+	assert.Equal(t, `zh-CN`, cookies[1].Value)
+	assert.Equal(t, `dark blue`, cookies[2].Value)
+	assert.Equal(t, `abc`, c.GetCookie(`SID`))
+
+	req2, res2 := test.NewRequestAndResponse(GET, "/")
+	c.Reset(req2, res2)
+	assert.Empty(t, c.Cookies())
+}
+
+func TestContextRequestBody(t *testing.T) {
+	e := New()
+	req := test.NewStdRequest(POST, "/")
+	req.Body = ioutil.NopCloser(strings.NewReader(`hello`))
+	c := e.NewContext(test.WrapRequest(req), test.WrapResponse(req, test.NewStdResponse()))
+	r1 := c.RequestBody()
+	r2 := c.RequestBody()
+	b, err := ioutil.ReadAll(r1)
+	assert.NoError(t, err)
+	assert.Equal(t, `hello`, string(b))
+	assert.NoError(t, r1.Close())
+	assert.NoError(t, r1.Close())
+	b, err = ioutil.ReadAll(r2)
+	assert.NoError(t, err)
+	assert.Equal(t, `hello`, string(b))
+	b, _ = ioutil.ReadAll(c.Request().Body())
+	assert.Equal(t, `hello`, string(b))
+
+	// the body is limited by the BodyLimit middleware
+	limited := mw.BodyLimit(`4B`)(HandlerFunc(func(c Context) error {
+		_, err := ioutil.ReadAll(c.RequestBody())
+		return err
+	}))
+	req = test.NewStdRequest(POST, "/")
+	req.Body = ioutil.NopCloser(strings.NewReader(`hello`))
+	c = e.NewContext(test.WrapRequest(req), test.WrapResponse(req, test.NewStdResponse()))
+	assert.Equal(t, ErrStatusRequestEntityTooLarge, limited.Handle(c))
+	// and the request body is not left empty
+	_, err = ioutil.ReadAll(c.Request().Body())
+	assert.Equal(t, ErrStatusRequestEntityTooLarge, err)
+
+	// the body is limited by default when the engine does not limit it
+	size := DefaultRequestBodyCacheSize
+	DefaultRequestBodyCacheSize = 4
+	defer func() {
+		DefaultRequestBodyCacheSize = size
+	}()
+	req = test.NewStdRequest(POST, "/")
+	req.Body = ioutil.NopCloser(strings.NewReader(`hello`))
+	c = e.NewContext(test.WrapRequest(req), test.WrapResponse(req, test.NewStdResponse()))
+	_, err = ioutil.ReadAll(c.RequestBody())
+	assert.Equal(t, ErrStatusRequestEntityTooLarge, err)
+}
+
+type testParams struct {
+	ID   int    `param:"id"`
+	Slug string `param:"slug"`
+	Page uint   `param:"page"`
+}
+
+func TestContextServeContent(t *testing.T) {
+	e := New()
+	modtime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	e.Get("/doc.txt", func(c Context) error {
+		return c.ServeContent(strings.NewReader(`0123456789`), `doc.txt`, modtime)
+	})
+	e.RebuildRouter()
+
+	rec := test.Request(GET, "/doc.txt", e, func(req *http.Request) {
+		req.Header.Set(`Range`, `bytes=2-5`)
+	})
+	assert.Equal(t, http.StatusPartialContent, rec.Code)
+	assert.Equal(t, `2345`, rec.Body.String())
+	assert.Equal(t, `bytes 2-5/10`, rec.Header().Get(`Content-Range`))
+	assert.Contains(t, rec.Header().Get(HeaderContentType), `text/plain`)
+
+	rec = test.Request(GET, "/doc.txt", e, func(req *http.Request) {
+		req.Header.Set(HeaderIfModifiedSince, modtime.Format(http.TimeFormat))
+	})
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+	assert.Empty(t, rec.Body.String())
+}
+
+type failingSeeker struct {
+	*strings.Reader
+}
+
+func (r failingSeeker) Read(b []byte) (int, error) {
+	return 0, errors.New(`read failed`)
+}
+
+func TestContextServeContentKeepBodyAndError(t *testing.T) {
+	e := New()
+	modtime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	var kept string
+	e.Get("/doc.txt", func(c Context) error {
+		c.Response().KeepBody(true)
+		err := c.ServeContent(strings.NewReader(`0123456789`), `doc.txt`, modtime)
+		kept = string(c.Response().Body())
+		return err
+	})
+	var serveErr error
+	e.Get("/fail.txt", func(c Context) error {
+		serveErr = c.ServeContent(failingSeeker{strings.NewReader(`0123456789`)}, `fail.txt`, modtime)
+		return nil
+	})
+	e.RebuildRouter()
+
+	rec := test.Request(GET, "/doc.txt", e)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `0123456789`, kept)
+
+	test.Request(GET, "/fail.txt", e)
+	assert.EqualError(t, serveErr, `read failed`)
+}
+
+func TestContextFullPath(t *testing.T) {
+	e := New()
+	e.Get("/users/:id", func(c Context) error {
+		return c.String(c.FullPath() + ` ` + c.Request().URL().Path())
+	})
+	var notFound string
+	e.SetHTTPErrorHandler(func(err error, c Context) {
+		notFound = c.FullPath()
+		c.String(err.Error(), http.StatusNotFound)
+	})
+	e.RebuildRouter()
+
+	rec := test.Request(GET, "/users/42", e)
+	assert.Equal(t, `/users/:id /users/42`, rec.Body.String())
+
+	rec = test.Request(GET, "/missing", e)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Empty(t, notFound)
+}
+
+type funcRenderer func(w io.Writer, name string, data interface{}, c Context) error
+
+func (f funcRenderer) Render(w io.Writer, name string, data interface{}, c Context) error {
+	return f(w, name, data, c)
+}
+
+func TestContextRenderStream(t *testing.T) {
+	e := New()
+	e.SetRenderFlushSize(8)
+	req := httptest.NewRequest(GET, "/", nil)
+	rec := httptest.NewRecorder()
+	var flushedBefore bool
+	var bodyBefore string
+	e.SetRenderer(funcRenderer(func(w io.Writer, name string, data interface{}, c Context) error {
+		io.WriteString(w, "\n  ")
+		io.WriteString(w, "<p>part1</p>")
+		flushedBefore = rec.Flushed
+		bodyBefore = rec.Body.String()
+		_, err := io.WriteString(w, "<p>part2</p>")
+		return err
+	}))
+	e.Get("/", func(c Context) error {
+		return c.Render("page", nil, http.StatusCreated)
+	})
+	e.RebuildRouter()
+	e.ServeHTTP(test.WrapRequest(req), test.WrapResponse(req, rec))
+
+	assert.True(t, flushedBefore)
+	assert.Equal(t, "<p>part1</p>", bodyBefore)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, "<p>part1</p><p>part2</p>", rec.Body.String())
+	assert.Contains(t, rec.Header().Get(HeaderContentType), MIMETextHTML)
+}
+
+func TestContextRenderStreamCompressed(t *testing.T) {
+	e := New()
+	e.SetRenderFlushSize(8)
+	req := httptest.NewRequest(GET, "/", nil)
+	req.Header.Set(HeaderAcceptEncoding, `gzip`)
+	rec := httptest.NewRecorder()
+	var flushedBefore string
+	e.SetRenderer(funcRenderer(func(w io.Writer, name string, data interface{}, c Context) error {
+		io.WriteString(w, "<p>part1</p>")
+		r, err := gzip.NewReader(bytes.NewReader(rec.Body.Bytes()))
+		if err == nil {
+			b, _ := ioutil.ReadAll(r)
+			flushedBefore = string(b)
+		}
+		_, err = io.WriteString(w, "<p>part2</p>")
+		return err
+	}))
+	e.Use(mw.Gzip())
+	e.Get("/", func(c Context) error {
+		return c.Render("page", nil)
+	})
+	e.RebuildRouter()
+	e.ServeHTTP(test.WrapRequest(req), test.WrapResponse(req, rec))
+
+	assert.Equal(t, "<p>part1</p>", flushedBefore)
+	assert.Equal(t, `gzip`, rec.Header().Get(HeaderContentEncoding))
+	r, err := gzip.NewReader(rec.Body)
+	assert.NoError(t, err)
+	b, _ := ioutil.ReadAll(r)
+	assert.Equal(t, "<p>part1</p><p>part2</p>", string(b))
+}
+
+func TestContextRenderWithoutRenderer(t *testing.T) {
+	e := New()
+	e.Get("/", func(c Context) error {
+		err := c.Render(`index`, nil)
+		assert.Equal(t, ErrRendererNotRegistered, err)
+		if assert.IsType(t, &HTTPError{}, err) {
+			assert.Equal(t, http.StatusInternalServerError, err.(*HTTPError).Code)
+			assert.Contains(t, err.Error(), `SetRenderer`)
+		}
+		return err
+	})
+	e.RebuildRouter()
+
+	rec := test.Request(GET, "/", e)
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
+
+func TestContextBindParams(t *testing.T) {
+	e := New()
+	e.Get("/posts/:id/:slug", func(c Context) error {
+		p := &testParams{}
+		if err := c.BindParams(p); err != nil {
+			return err
+		}
+		return c.JSON(H{`id`: p.ID, `slug`: p.Slug, `page`: p.Page})
+	})
+	e.RebuildRouter()
+
+	rec := test.Request(GET, "/posts/42/hello-world", e)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"id":42,"slug":"hello-world","page":0}`, rec.Body.String())
+
+	rec = test.Request(GET, "/posts/abc/hello-world", e)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+type Pagination struct {
+	Page int `param:"page" header:"X-Page"`
+	Size int `header:"X-Page-Size"`
+}
+
+type Sorting struct {
+	Order string `param:"order" header:"X-Order"`
+}
+
+type testListQuery struct {
+	Pagination
+	*Sorting
+	Keyword string `param:"keyword" header:"X-Keyword"`
+}
+
+func TestContextBindEmbedded(t *testing.T) {
+	e := New()
+	e.Get("/search/:keyword/:page", func(c Context) error {
+		q := &testListQuery{}
+		if err := c.BindParams(q); err != nil {
+			return err
+		}
+		return c.JSON(H{`keyword`: q.Keyword, `page`: q.Page, `sorted`: q.Sorting != nil})
+	})
+	e.Get("/headers", func(c Context) error {
+		q := &testListQuery{}
+		if err := c.BindHeaders(q); err != nil {
+			return err
+		}
+		return c.JSON(H{`keyword`: q.Keyword, `page`: q.Page, `size`: q.Size, `order`: q.Order})
+	})
+	e.Get("/query", func(c Context) error {
+		q := &struct {
+			Pagination
+			Keyword string
+		}{}
+		if err := c.Bind(q); err != nil {
+			return err
+		}
+		return c.JSON(H{`keyword`: q.Keyword, `page`: q.Page, `size`: q.Size})
+	})
+	e.Get("/form", func(c Context) error {
+		q := &testListQuery{}
+		if err := c.Bind(q); err != nil {
+			return err
+		}
+		return c.JSON(H{`keyword`: q.Keyword, `order`: q.Order})
+	})
+	e.RebuildRouter()
+
+	rec := test.Request(GET, "/search/echo/3", e)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"keyword":"echo","page":3,"sorted":false}`, rec.Body.String())
+
+	rec = test.Request(GET, "/search/echo/x", e)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = test.Request(GET, "/headers", e, func(req *http.Request) {
+		req.Header.Set(`X-Keyword`, `echo`)
+		req.Header.Set(`X-Page`, `2`)
+		req.Header.Set(`X-Page-Size`, `20`)
+		req.Header.Set(`X-Order`, `desc`)
+	})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"keyword":"echo","page":2,"size":20,"order":"desc"}`, rec.Body.String())
+
+	rec = test.Request(GET, "/query?keyword=echo&page=4&size=10", e)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"keyword":"echo","page":4,"size":10}`, rec.Body.String())
+
+	rec = test.Request(GET, "/form?keyword=echo&order=asc", e)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"keyword":"echo","order":"asc"}`, rec.Body.String())
+}
+
+type testColor string
+
+func (c *testColor) UnmarshalText(b []byte) error {
+	switch v := string(b); v {
+	case `red`, `green`:
+		*c = testColor(v)
+		return nil
+	}
+	return errors.New(`unknown color`)
+}
+
+func TestContextBindUnmarshaler(t *testing.T) {
+	e := New()
+	e.Get("/paint/:color", func(c Context) error {
+		p := &struct {
+			Color  testColor  `param:"color"`
+			Border *testColor `header:"X-Border"`
+		}{}
+		if err := c.BindParams(p); err != nil {
+			return err
+		}
+		if err := c.BindHeaders(p); err != nil {
+			return err
+		}
+		return c.String(string(p.Color) + `,` + string(*p.Border))
+	})
+	e.RebuildRouter()
+
+	rec := test.Request(GET, "/paint/red", e, func(req *http.Request) {
+		req.Header.Set(`X-Border`, `green`)
+	})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `red,green`, rec.Body.String())
+
+	rec = test.Request(GET, "/paint/blue", e)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestContextQueryString(t *testing.T) {
+	e := New()
+	raw := "b=2&a=1&a=%2F%20x&c=%E4%BD%A0+y&empty="
+	req := test.NewStdRequest(GET, "/sign?"+raw)
+	c := e.NewContext(test.WrapRequest(req), test.WrapResponse(req, test.NewStdResponse()))
+	assert.Equal(t, raw, c.QueryString())
+	assert.Equal(t, "/ x", c.QueryValues("a")[1])
+
+	req = test.NewStdRequest(GET, "/sign")
+	c = e.NewContext(test.WrapRequest(req), test.WrapResponse(req, test.NewStdResponse()))
+	assert.Equal(t, "", c.QueryString())
+}
+
+func TestContextJSONContentLength(t *testing.T) {
+	e := New()
+	e.Get("/small", func(c Context) error {
+		return c.JSON(H{"name": "echo"})
+	})
+	e.Get("/large", func(c Context) error {
+		return c.JSON(H{"name": strings.Repeat("a", 100)})
+	})
+	e.RebuildRouter()
+
+	rec := test.Request(GET, "/small", e)
+	assert.Empty(t, rec.Header().Get(HeaderContentLength))
+
+	e.SetJSONContentLengthLimit(64)
+	rec = test.Request(GET, "/small", e)
+	assert.Equal(t, `{"name":"echo"}`, rec.Body.String())
+	assert.Equal(t, "15", rec.Header().Get(HeaderContentLength))
+
+	rec = test.Request(GET, "/large", e)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Header().Get(HeaderContentLength))
+	assert.Equal(t, MIMEApplicationJSONCharsetUTF8, rec.Header().Get(HeaderContentType))
+	assert.Equal(t, `{"name":"`+strings.Repeat("a", 100)+`"}`, strings.TrimSpace(rec.Body.String()))
+}
+
+func TestContextJSONOmitEmpty(t *testing.T) {
+	type profile struct {
+		Bio     string   `json:"bio"`
+		Website *string  `json:"website"`
+		Tags    []string `json:"tags"`
+	}
+	type user struct {
+		ID      int      `json:"id"`
+		Name    string   `json:"name"`
+		Email   *string  `json:"email"`
+		Profile profile  `json:"profile"`
+		Roles   []string `json:"roles"`
+	}
+	data := user{ID: 1, Name: "Jon", Roles: []string{"admin"}}
+	e := New()
+	e.Get("/full", func(c Context) error {
+		return c.JSON(data)
+	})
+	e.Get("/compact", func(c Context) error {
+		return c.JSONOmitEmpty(data, http.StatusCreated)
+	})
+	e.RebuildRouter()
+
+	rec := test.Request(GET, "/full", e)
+	assert.Equal(t, `{"id":1,"name":"Jon","email":null,"profile":{"bio":"","website":null,"tags":null},"roles":["admin"]}`, rec.Body.String())
+
+	rec = test.Request(GET, "/compact", e)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, MIMEApplicationJSONCharsetUTF8, rec.Header().Get(HeaderContentType))
+	assert.Equal(t, `{"id":1,"name":"Jon","roles":["admin"]}`, rec.Body.String())
+}
+
+func TestContextOnWrite(t *testing.T) {
+	e := New()
+	var total, calls int
+	e.Get("/download", func(c Context) error {
+		c.OnWrite(func(n int) {
+			total += n
+			calls++
+		})
+		return c.Blob(bytes.Repeat([]byte("a"), 100<<10))
+	})
+	e.RebuildRouter()
+
+	rec := test.Request(GET, "/download", e)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, 100<<10, rec.Body.Len())
+	assert.Equal(t, 100<<10, total)
+	assert.True(t, calls > 1)
+}
+
+func TestContextCacheControl(t *testing.T) {
+	e := New()
+	c := e.NewContext(test.NewRequestAndResponse(GET, "/"))
+	c.Public()
+	c.MaxAge(time.Hour)
+	c.CacheControl("must-revalidate", "public")
+	assert.Equal(t, "public, max-age=3600, must-revalidate", c.Response().Header().Get(HeaderCacheControl))
+
+	c.Private()
+	c.NoStore()
+	c.MaxAge(time.Minute)
+	assert.Equal(t, "must-revalidate, private, no-store", c.Response().Header().Get(HeaderCacheControl))
+}
+
+type ListArgs struct {
+	Page  int    `query:"page" default:"1"`
+	Size  int    `query:"size" default:"10"`
+	Sort  string `query:"sort" default:"id"`
+	Token string `header:"X-Token" default:"anonymous"`
+}
+
+func TestContextBindDefault(t *testing.T) {
+	e := New()
+	req := test.NewStdRequest(GET, "/list?size=20&page=")
+	c := e.NewContext(test.WrapRequest(req), test.WrapResponse(req, test.NewStdResponse()))
+	args := &ListArgs{}
+	assert.NoError(t, c.BindQuery(args))
+	assert.NoError(t, c.BindHeaders(args))
+	assert.Equal(t, 20, args.Size)
+	assert.Equal(t, "id", args.Sort)
+	assert.Equal(t, "anonymous", args.Token)
+	// an empty value is absent
+	assert.Equal(t, 1, args.Page)
+
+	req = test.NewStdRequest(GET, "/list?sort=name")
+	req.Header.Set("X-Token", "abc")
+	c = e.NewContext(test.WrapRequest(req), test.WrapResponse(req, test.NewStdResponse()))
+	args = &ListArgs{Size: 5}
+	assert.NoError(t, c.BindQuery(args))
+	assert.NoError(t, c.BindHeaders(args))
+	assert.Equal(t, 1, args.Page)
+	assert.Equal(t, 5, args.Size)
+	assert.Equal(t, "name", args.Sort)
+	assert.Equal(t, "abc", args.Token)
+
+	bad := &struct {
+		Size int `query:"size" default:"ten"`
+	}{}
+	assert.Error(t, c.BindQuery(bad))
+
+	// the nil embedded struct pointer is allocated for its defaults
+	embedded := &struct {
+		*ListArgs
+	}{}
+	assert.NoError(t, c.BindQuery(embedded))
+	if assert.NotNil(t, embedded.ListArgs) {
+		assert.Equal(t, 1, embedded.Page)
+		assert.Equal(t, 10, embedded.Size)
+		assert.Equal(t, "name", embedded.Sort)
+	}
+
+	// a nil embedded pointer of an unexported type can not be set
+	type listArgs ListArgs
+	unexported := &struct {
+		*listArgs
+	}{}
+	assert.NoError(t, c.BindQuery(unexported))
+	assert.Nil(t, unexported.listArgs)
+}
+
+func TestContextBindQueryStructSlice(t *testing.T) {
+	type filter struct {
+		Field string `query:"field"`
+		Op    string `query:"op" default:"eq"`
+		Value string `query:"value"`
+	}
+	args := &struct {
+		Filters []filter  `query:"filters"`
+		Sorts   []*filter `query:"sorts"`
+		Page    int       `query:"page"`
+	}{}
+	e := New()
+	req := test.NewStdRequest(GET, "/list?filters[0][field]=name&filters[0][op]=eq&filters[0][value]=jon&filters[1][field]=age&filters[1][op]=gt&filters[1][value]=18&filters[3][field]=skipped&sorts[0][field]=id&page=2")
+	c := e.NewContext(test.WrapRequest(req), test.WrapResponse(req, test.NewStdResponse()))
+	assert.NoError(t, c.BindQuery(args))
+	assert.Equal(t, []filter{
+		{Field: "name", Op: "eq", Value: "jon"},
+		{Field: "age", Op: "gt", Value: "18"},
+	}, args.Filters)
+	assert.Len(t, args.Sorts, 1)
+	assert.Equal(t, filter{Field: "id", Op: "eq"}, *args.Sorts[0])
+	assert.Equal(t, 2, args.Page)
+
+	req = test.NewStdRequest(GET, "/list?page=3")
+	c = e.NewContext(test.WrapRequest(req), test.WrapResponse(req, test.NewStdResponse()))
+	assert.NoError(t, c.BindQuery(args))
+	assert.Len(t, args.Filters, 2)
+	assert.Equal(t, 3, args.Page)
+}
+
+func TestContextBindNDJSON(t *testing.T) {
+	e := New()
+	var names []string
+	var skip bool
+	h := func(c Context) error {
+		return c.BindNDJSON(func(decode func(interface{}) error) error {
+			for {
+				record := struct {
+					Name string `json:"name"`
+				}{}
+				err := decode(&record)
+				if err == io.EOF {
+					return c.String(strconv.Itoa(len(names)))
+				}
+				if err != nil {
+					if skip {
+						continue
+					}
+					return err
+				}
+				names = append(names, record.Name)
+			}
+		})
+	}
+	e.Post("/ingest", h)
+	e.Post("/ingest/limited", h, mw.BodyLimit(`16B`))
+	e.RebuildRouter()
+
+	ingestTo := func(path string, body string) *httptest.ResponseRecorder {
+		names = nil
+		return test.Request(POST, path, e, func(req *http.Request) {
+			req.Header.Set(HeaderContentType, MIMEApplicationNDJSON)
+			req.Body = ioutil.NopCloser(strings.NewReader(body))
+		})
+	}
+	ingest := func(body string) *httptest.ResponseRecorder {
+		return ingestTo("/ingest", body)
+	}
+	rec := ingest("{\"name\":\"a\"}\n\n{\"name\":\"b\"}\r\n{\"name\":\"c\"}")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "3", rec.Body.String())
+	assert.Equal(t, []string{"a", "b", "c"}, names)
+
+	body := "{\"name\":\"a\"}\n{\"name\":\n{\"name\":\"c\"}\n"
+	rec = ingest(body)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "line 2")
+	assert.Equal(t, []string{"a"}, names)
+
+	skip = true
+	rec = ingest(body)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, []string{"a", "c"}, names)
+	skip = false
+
+	e.SetJSONDecodeOptions(JSONDecodeOptions{DisallowUnknownFields: true})
+	rec = ingest("{\"name\":\"a\"}\n{\"name\":\"b\",\"age\":1}\n")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "line 2")
+
+	rec = ingestTo("/ingest/limited", strings.Repeat("a", 64))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	assert.Empty(t, names)
+}
+
+// configEngine is an engine with a config, e.g. for the request body size limit.
+type configEngine struct {
+	drainEngine
+	config *engine.Config
+}
+
+func (e configEngine) Config() *engine.Config {
+	return e.config
+}
+
+func TestContextBindNDJSONStream(t *testing.T) {
+	e := New()
+	assert.NoError(t, e.Run(configEngine{config: &engine.Config{MaxRequestBodySize: 64}}))
+	names := make(chan string, 2)
+	e.Post("/ingest", func(c Context) error {
+		return c.BindNDJSON(func(decode func(interface{}) error) error {
+			for {
+				record := struct {
+					Name string `json:"name"`
+				}{}
+				err := decode(&record)
+				if err == io.EOF {
+					return c.String("done")
+				}
+				if err != nil {
+					return err
+				}
+				names <- record.Name
+			}
+		})
+	})
+	e.RebuildRouter()
+
+	ingest := func(body io.Reader) <-chan *httptest.ResponseRecorder {
+		served := make(chan *httptest.ResponseRecorder, 1)
+		go func() {
+			served <- test.Request(POST, "/ingest", e, func(req *http.Request) {
+				req.Header.Set(HeaderContentType, MIMEApplicationNDJSON)
+				req.Body = ioutil.NopCloser(body)
+			})
+		}()
+		return served
+	}
+
+	// the first record reaches the handler while the body is still being sent
+	pr, pw := io.Pipe()
+	served := ingest(pr)
+	pw.Write([]byte("{\"name\":\"a\"}\n"))
+	select {
+	case name := <-names:
+		assert.Equal(t, "a", name)
+	case <-time.After(time.Second):
+		pw.Close()
+		t.Fatal("the first record was not decoded before the end of the body")
+	}
+	pw.Write([]byte("{\"name\":\"b\"}\n"))
+	pw.Close()
+	assert.Equal(t, "b", <-names)
+	rec := <-served
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "done", rec.Body.String())
+
+	// a body over the limit is rejected once the limit is read
+	rec = <-ingest(strings.NewReader("{\"name\":\"a\"}\n" + strings.Repeat(" ", 64) + "\n"))
+	assert.Equal(t, "a", <-names)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+}
+
+func TestContextAccepts(t *testing.T) {
+	e := New()
+	newContext := func(accept string) Context {
+		req := test.NewStdRequest(GET, "/")
+		req.Header.Set(HeaderAccept, accept)
+		return e.NewContext(test.WrapRequest(req), test.WrapResponse(req, test.NewStdResponse()))
+	}
+	assert.Equal(t, `json`, newContext(MIMEApplicationJSON).Accepts(`html`, `json`))
+	assert.Equal(t, `html`, newContext(`text/html,application/json`).Accepts(`html`, `json`))
+	assert.Equal(t, `xml`, newContext(`*/*`).Accepts(`xml`, `json`))
+	assert.Equal(t, `html`, newContext(``).Accepts(`html`, `json`))
+	assert.Equal(t, ``, newContext(MIMETextPlain).Accepts(`html`, `json`))
+}
+
+func TestContextSetFormat(t *testing.T) {
+	e := New()
+	e.Use(func(h Handler) HandlerFunc {
+		return func(c Context) error {
+			c.SetFormat(`xml`)
+			return h.Handle(c)
+		}
+	})
+	e.Get("/accepts", func(c Context) error {
+		return c.String(c.Format() + `,` + c.Accepts(`json`, `xml`))
+	})
+	e.Get("/fail", func(c Context) error {
+		return NewHTTPError(http.StatusBadRequest, `invalid input`)
+	})
+	e.RebuildRouter()
+
+	rec := test.Request(GET, "/accepts", e, func(req *http.Request) {
+		req.Header.Set(HeaderAccept, MIMEApplicationJSON)
+	})
+	assert.Equal(t, `xml,xml`, rec.Body.String())
+
+	rec = test.Request(GET, "/fail", e, func(req *http.Request) {
+		req.Header.Set(HeaderAccept, MIMEApplicationJSON)
+	})
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Header().Get(HeaderContentType), MIMEApplicationXML)
+	assert.Contains(t, rec.Body.String(), `<Info>invalid input</Info>`)
+}
+
+func TestContextSubRequest(t *testing.T) {
+	e := New()
+	e.Get("/users/:id", func(c Context) error {
+		c.Response().Header().Set(`X-User`, c.Param(`id`))
+		c.Response().Header().Set(`X-Auth`, c.Header(HeaderAuthorization))
+		c.Response().Header().Set(`X-Range`, c.Header(`Range`)+c.Header(HeaderAcceptEncoding))
+		return c.String(`user:` + c.Param(`id`))
+	})
+	e.Get("/headers", func(c Context) error {
+		resp, err := c.SubRequest(GET, "/users/7", nil)
+		if err != nil {
+			return err
+		}
+		return c.String(resp.Header.Get(`X-Auth`) + `|` + resp.Header.Get(`X-Range`))
+	})
+	e.Get("/profile", func(c Context) error {
+		resp, err := c.SubRequest(GET, "/users/7", nil)
+		if err != nil {
+			return err
+		}
+		return c.String(resp.Header.Get(`X-User`)+`|`+string(resp.Body), resp.Code)
+	})
+	e.Get("/missing", func(c Context) error {
+		resp, err := c.SubRequest(GET, "/nothing", nil)
+		if err != nil {
+			return err
+		}
+		return c.NoContent(resp.Code)
+	})
+	e.RebuildRouter()
+
+	rec := test.Request(GET, "/profile", e)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `7|user:7`, rec.Body.String())
+
+	rec = test.Request(GET, "/missing", e)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	// only the allowed headers reach the sub request
+	rec = test.Request(GET, "/headers", e, func(req *http.Request) {
+		req.Header.Set(HeaderAuthorization, `Bearer token`)
+		req.Header.Set(`Range`, `bytes=0-1`)
+		req.Header.Set(HeaderAcceptEncoding, `gzip`)
+	})
+	assert.Equal(t, `Bearer token|`, rec.Body.String())
+}
+
+type testXMLFeed struct {
+	XMLName xml.Name `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string   `xml:"title"`
+	Link    struct {
+		Href string `xml:"href,attr"`
+	} `xml:"link"`
+}
+
+func TestContextBindXML(t *testing.T) {
+	e := New()
+	body := `<?xml version="1.0"?><feed xmlns="http://www.w3.org/2005/Atom"><title>news</title><link href="https://www.webx.top/"/></feed>`
+	for _, contentType := range []string{MIMEApplicationXML, MIMETextXML + "; charset=utf-8"} {
+		req := test.NewStdRequest(POST, "/")
+		req.Header.Set(HeaderContentType, contentType)
+		req.Body = ioutil.NopCloser(strings.NewReader(body))
+		c := e.NewContext(test.WrapRequest(req), test.WrapResponse(req, test.NewStdResponse()))
+		feed := &testXMLFeed{}
+		assert.NoError(t, c.MustBind(feed))
+		assert.Equal(t, `http://www.w3.org/2005/Atom`, feed.XMLName.Space)
+		assert.Equal(t, `news`, feed.Title)
+		assert.Equal(t, `https://www.webx.top/`, feed.Link.Href)
+	}
+
+	req := test.NewStdRequest(POST, "/")
+	req.Header.Set(HeaderContentType, MIMEApplicationXML)
+	req.Body = ioutil.NopCloser(strings.NewReader(`<feed><title>news</feed>`))
+	c := e.NewContext(test.WrapRequest(req), test.WrapResponse(req, test.NewStdResponse()))
+	err := c.MustBind(&testXMLFeed{})
+	assert.IsType(t, &HTTPError{}, err)
+	assert.Equal(t, http.StatusBadRequest, err.(*HTTPError).Code)
+}
+
+type testUser struct {
+	Name string `json:"name"`
+}
+
+func TestContextBindEncodedBody(t *testing.T) {
+	e := New()
+	// base64 stands in for a real zstd decoder here.
+	e.AddRequestDecoder(`zstd`, func(r io.Reader) (io.Reader, error) {
+		return base64.NewDecoder(base64.StdEncoding, r), nil
+	})
+	newContext := func(encoding string, body []byte) Context {
+		req := test.NewStdRequest(POST, "/")
+		req.Header.Set(HeaderContentType, MIMEApplicationJSON)
+		req.Header.Set(HeaderContentEncoding, encoding)
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+		return e.NewContext(test.WrapRequest(req), test.WrapResponse(req, test.NewStdResponse()))
+	}
+	raw := `{"name":"webx"}`
+
+	buf := new(bytes.Buffer)
+	gw := gzip.NewWriter(buf)
+	gw.Write([]byte(raw))
+	gw.Close()
+	user := &testUser{}
+	assert.NoError(t, newContext(`gzip`, buf.Bytes()).MustBind(user))
+	assert.Equal(t, `webx`, user.Name)
+
+	user = &testUser{}
+	c := newContext(`zstd`, []byte(base64.StdEncoding.EncodeToString([]byte(raw))))
+	assert.NoError(t, c.MustBind(user))
+	assert.Equal(t, `webx`, user.Name)
+	assert.Empty(t, c.Header(HeaderContentEncoding))
+
+	err := newContext(`br`, []byte(raw)).Bind(&testUser{})
+	assert.IsType(t, &HTTPError{}, err)
+	assert.Equal(t, http.StatusUnsupportedMediaType, err.(*HTTPError).Code)
+}
+
+type testCloseRecorder struct {
+	io.Reader
+	closed bool
+}
+
+func (r *testCloseRecorder) Close() error {
+	r.closed = true
+	return nil
+}
+
+func TestContextRequestBodyDecoded(t *testing.T) {
+	e := New()
+	e.SetRequestDecoders(nil)
+	e.AddRequestDecoder(`GZIP`, DefaultRequestDecoders[`gzip`])
+	newContext := func(raw string) (Context, *testCloseRecorder) {
+		buf := new(bytes.Buffer)
+		gw := gzip.NewWriter(buf)
+		gw.Write([]byte(raw))
+		gw.Close()
+		body := &testCloseRecorder{Reader: buf}
+		req := test.NewStdRequest(POST, "/")
+		req.Header.Set(HeaderContentEncoding, `gzip`)
+		req.Body = body
+		return e.NewContext(test.WrapRequest(req), test.WrapResponse(req, test.NewStdResponse())), body
+	}
+
+	c, original := newContext(`hello`)
+	b, err := ioutil.ReadAll(c.RequestBody())
+	assert.NoError(t, err)
+	assert.Equal(t, `hello`, string(b))
+	assert.True(t, original.closed)
+}
+
+func TestContextBindJSONDecodeOptions(t *testing.T) {
+	e := New()
+	newContext := func(body string) Context {
+		req := test.NewStdRequest(POST, "/")
+		req.Header.Set(HeaderContentType, MIMEApplicationJSON)
+		req.Body = ioutil.NopCloser(strings.NewReader(body))
+		return e.NewContext(test.WrapRequest(req), test.WrapResponse(req, test.NewStdResponse()))
+	}
+
+	user := &testUser{}
+	assert.NoError(t, newContext(`{"name":"webx","unknown":1}`).MustBind(user))
+	assert.Equal(t, `webx`, user.Name)
+
+	e.SetJSONDecodeOptions(JSONDecodeOptions{DisallowUnknownFields: true})
+	err := newContext(`{"name":"webx","unknown":1}`).MustBind(&testUser{})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown`)
+
+	e.SetJSONDecodeOptions(JSONDecodeOptions{UseNumber: true})
+	data := map[string]interface{}{}
+	assert.NoError(t, newContext(`{"id":9007199254740993}`).MustBind(&data))
+	assert.Equal(t, json.Number(`9007199254740993`), data[`id`])
+}
+
+func TestContextBindJSONIgnoresContentType(t *testing.T) {
+	e := New()
+	newContext := func(body string) Context {
+		req := test.NewStdRequest(POST, "/")
+		req.Header.Set(HeaderContentType, MIMETextPlain)
+		req.Body = ioutil.NopCloser(strings.NewReader(body))
+		return e.NewContext(test.WrapRequest(req), test.WrapResponse(req, test.NewStdResponse()))
+	}
+
+	user := &testUser{}
+	assert.NoError(t, newContext(`{"name":"webx"}`).BindJSON(user))
+	assert.Equal(t, `webx`, user.Name)
+
+	err := newContext(`{"name":`).BindJSON(&testUser{})
+	assert.IsType(t, &HTTPError{}, err)
+	assert.Equal(t, http.StatusBadRequest, err.(*HTTPError).Code)
+
+	bindLimited := mw.BodyLimit(`8B`)(HandlerFunc(func(c Context) error {
+		return c.BindJSON(&testUser{})
+	}))
+	err = bindLimited.Handle(newContext(`{"name":"webx"}`))
+	assert.Equal(t, ErrStatusRequestEntityTooLarge, err)
+}
+
+func TestContextBindPartial(t *testing.T) {
+	e := New()
+	req := test.NewStdRequest(PATCH, "/")
+	req.Header.Set(HeaderContentType, MIMEApplicationJSON)
+	req.Body = ioutil.NopCloser(strings.NewReader(`{"name":""}`))
+	c := e.NewContext(test.WrapRequest(req), test.WrapResponse(req, test.NewStdResponse()))
+
+	user := &testUser{Name: `old`}
+	fields, err := c.BindPartial(user)
+	assert.NoError(t, err)
+	assert.Equal(t, ``, user.Name)
+	assert.True(t, fields.Has(`name`))
+	assert.False(t, fields.Has(`age`))
+	assert.Len(t, fields, 1)
+}
+
+type testFieldError struct {
+	field string
+	tag   string
+}
+
+func (e testFieldError) Field() string {
+	return e.field
+}
+
+func (e testFieldError) Error() string {
+	return e.field + ` failed on the '` + e.tag + `' tag`
+}
+
+type testFieldErrors []testFieldError
+
+type testValidator struct{}
+
+func (testValidator) Validate(i interface{}, args ...string) ValidateResult {
+	user := i.(*testSignup)
+	var errs testFieldErrors
+	if len(user.Name) == 0 {
+		errs = append(errs, testFieldError{field: `name`, tag: `required`})
+	}
+	if !strings.Contains(user.Email, `@`) {
+		errs = append(errs, testFieldError{field: `email`, tag: `email`})
+	}
+	result := NewValidateResult()
+	if len(errs) > 0 {
+		result.SetError(errs[0]).SetField(errs[0].field).SetRaw(errs)
+	}
+	return result
+}
+
+type testSignup struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+func TestContextBindAndValidate(t *testing.T) {
+	e := New()
+	e.SetValidator(testValidator{})
+	e.Post("/signup", func(c Context) error {
+		user := &testSignup{}
+		if err := c.BindAndValidate(user); err != nil {
+			return err
+		}
+		return c.String(user.Name)
+	})
+	e.RebuildRouter()
+
+	newRequest := func(body string) func(*http.Request) {
+		return func(req *http.Request) {
+			req.Header.Set(HeaderContentType, MIMEApplicationJSON)
+			req.Body = ioutil.NopCloser(strings.NewReader(body))
+		}
+	}
+	rec := test.Request(POST, "/signup", e, newRequest(`{"name":"webx","email":"webx@webx.top"}`))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `webx`, rec.Body.String())
+
+	rec = test.Request(POST, "/signup", e, newRequest(`{"email":"webx"}`))
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.JSONEq(t, `{"errors":{"name":"name failed on the 'required' tag","email":"email failed on the 'email' tag"}}`, rec.Body.String())
+}
+
+func TestContextCustomBinder(t *testing.T) {
+	const contentType = "application/vnd.myapp+json"
+	e := New()
+	binder := e.Binder().(interface {
+		AddDecoder(string, func(interface{}, Context, ...FormDataFilter) error)
+	})
+	binder.AddDecoder(contentType, func(i interface{}, c Context, _ ...FormDataFilter) error {
+		envelope := struct {
+			Data json.RawMessage `json:"data"`
+		}{}
+		if err := json.NewDecoder(c.Request().Body()).Decode(&envelope); err != nil {
+			return NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		return json.Unmarshal(envelope.Data, i)
+	})
+	e.Post("/signup", func(c Context) error {
+		user := &testSignup{}
+		if err := c.MustBind(user); err != nil {
+			return err
+		}
+		return c.String(user.Name + "|" + user.Email)
+	})
+	e.RebuildRouter()
+
+	rec := test.Request(POST, "/signup", e, func(req *http.Request) {
+		req.Header.Set(HeaderContentType, contentType+"; charset=utf-8")
+		req.Body = ioutil.NopCloser(strings.NewReader(`{"data":{"name":"webx","email":"webx@webx.top"}}`))
+	})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "webx|webx@webx.top", rec.Body.String())
+
+	rec = test.Request(POST, "/signup", e, func(req *http.Request) {
+		req.Header.Set(HeaderContentType, "application/vnd.other+json")
+		req.Body = ioutil.NopCloser(strings.NewReader(`{"data":{"name":"webx"}}`))
+	})
+	// falls back to the default decoders
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "|", rec.Body.String())
+}
+
+func TestContextBindHook(t *testing.T) {
+	e := New()
+	e.SetValidator(testValidator{})
+	e.SetBindHook(func(i interface{}, c Context) error {
+		if user, ok := i.(*testSignup); ok {
+			user.Name = strings.TrimSpace(user.Name)
+			user.Email = strings.ToLower(strings.TrimSpace(user.Email))
+		}
+		return nil
+	})
+	e.Post("/signup", func(c Context) error {
+		user := &testSignup{}
+		if err := c.BindAndValidate(user); err != nil {
+			return err
+		}
+		return c.String(user.Name + `|` + user.Email)
+	})
+	e.RebuildRouter()
+
+	newRequest := func(body string) func(*http.Request) {
+		return func(req *http.Request) {
+			req.Header.Set(HeaderContentType, MIMEApplicationJSON)
+			req.Body = ioutil.NopCloser(strings.NewReader(body))
+		}
+	}
+	rec := test.Request(POST, "/signup", e, newRequest(`{"name":"  webx ","email":" WEBX@webx.top "}`))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `webx|webx@webx.top`, rec.Body.String())
+
+	// the hook runs before validation
+	rec = test.Request(POST, "/signup", e, newRequest(`{"name":"   ","email":"webx@webx.top"}`))
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+
+	e.SetBindHook(func(i interface{}, c Context) error {
+		return NewHTTPError(http.StatusBadRequest, `bad`)
+	})
+	rec = test.Request(POST, "/signup", e, newRequest(`{"name":"webx","email":"webx@webx.top"}`))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+type closedConnWriter struct{}
+
+func (closedConnWriter) Write(b []byte) (int, error) {
+	return 0, &net.OpError{Op: `write`, Net: `tcp`, Err: os.NewSyscallError(`write`, syscall.EPIPE)}
+}
+
+func TestContextClientDisconnect(t *testing.T) {
+	e := New()
+	var handled []error
+	e.SetHTTPErrorHandler(func(err error, c Context) {
+		handled = append(handled, err)
+	})
+	e.Get("/", func(c Context) error {
+		c.Response().SetWriter(closedConnWriter{})
+		return c.String(`hello`)
+	})
+	e.Get("/fail", func(c Context) error {
+		return ErrNotFound
+	})
+	upstreamErr := &net.OpError{Op: `read`, Net: `tcp`, Err: os.NewSyscallError(`read`, syscall.ECONNRESET)}
+	e.Get("/upstream", func(c Context) error {
+		return upstreamErr
+	})
+	e.RebuildRouter()
+
+	test.Request(GET, "/", e)
+	assert.Empty(t, handled)
+
+	test.Request(GET, "/fail", e)
+	assert.Equal(t, []error{ErrNotFound}, handled)
+
+	test.Request(GET, "/upstream", e)
+	assert.Equal(t, []error{ErrNotFound, upstreamErr}, handled)
+
+	assert.True(t, IsClientDisconnect(syscall.ECONNRESET))
+	assert.False(t, IsClientDisconnect(ErrNotFound))
+}
+
+type testPanicMarshaler struct{}
+
+func (testPanicMarshaler) MarshalJSON() ([]byte, error) {
+	panic(`boom`)
+}
+
+func TestContextMarshalError(t *testing.T) {
+	e := New()
+	e.Get("/json", func(c Context) error {
+		return c.JSON(H{`ch`: make(chan int)})
+	})
+	e.Get("/jsonp", func(c Context) error {
+		return c.JSONP(`callback`, H{`fn`: func() {}})
+	})
+	e.Get("/xml", func(c Context) error {
+		return c.XML(make(chan int))
+	})
+	e.Get("/panic", func(c Context) error {
+		return c.JSON(testPanicMarshaler{})
+	})
+	e.RebuildRouter()
+
+	for path, format := range map[string]string{
+		"/json":  `JSON`,
+		"/jsonp": `JSONP`,
+		"/xml":   `XML`,
+		"/panic": `JSON`,
+	} {
+		e.SetDebug(false)
+		rec := test.Request(GET, path, e)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code, path)
+		assert.Equal(t, http.StatusText(http.StatusInternalServerError), rec.Body.String(), path)
+
+		e.SetDebug(true)
+		rec = test.Request(GET, path, e)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code, path)
+		assert.True(t, strings.HasPrefix(rec.Body.String(), `failed to marshal `+format+` response: `), path)
+	}
+}
+
+type cancelWriter struct {
+	w      *bytes.Buffer
+	cancel context.CancelFunc
+}
+
+func (w *cancelWriter) Write(b []byte) (int, error) {
+	w.cancel()
+	return w.w.Write(b)
+}
+
+func TestContextBlobAbortsOnDeadline(t *testing.T) {
+	e := New()
+	req, res := test.NewRequestAndResponse(GET, "/")
+	c := e.NewContext(req, res)
+	ctx, cancel := context.WithCancel(context.Background())
+	c.SetStdContext(ctx)
+	buf := new(bytes.Buffer)
+	res.SetWriter(&cancelWriter{w: buf, cancel: cancel})
+	err := c.Blob(bytes.Repeat([]byte(`a`), 1<<20))
+	assert.Equal(t, context.Canceled, err)
+	assert.True(t, buf.Len() > 0)
+	assert.True(t, buf.Len() < 1<<20)
+
+	req, res = test.NewRequestAndResponse(GET, "/")
+	c.Reset(req, res)
+	ctx, cancel = context.WithTimeout(context.Background(), 0)
+	defer cancel()
+	c.SetStdContext(ctx)
+	assert.Equal(t, context.DeadlineExceeded, c.String(`late`))
+	assert.False(t, res.Committed())
+}
+
+type pushRecorder struct {
+	http.ResponseWriter
+	targets []string
+	flushed bool
+}
+
+func (p *pushRecorder) Push(target string, opts *http.PushOptions) error {
+	p.targets = append(p.targets, target)
+	return nil
+}
+
+func (p *pushRecorder) Flush() {
+	p.flushed = true
+}
+
+func TestContextPush(t *testing.T) {
+	e := New()
+	req := test.NewStdRequest(GET, "/")
+	rec := &pushRecorder{ResponseWriter: test.NewStdResponse()}
+	c := e.NewContext(test.WrapRequest(req), test.WrapResponse(req, rec))
+	assert.NoError(t, c.Push(`/static/app.css`, nil))
+	assert.NoError(t, c.Push(`/static/app.js`, &http.PushOptions{Method: GET}))
+	assert.Equal(t, []string{`/static/app.css`, `/static/app.js`}, rec.targets)
+	c.Flush()
+	assert.True(t, rec.flushed)
+
+	// httptest.ResponseRecorder does not implement http.Pusher
+	c = e.NewContext(test.NewRequestAndResponse(GET, "/"))
+	assert.Equal(t, http.ErrNotSupported, c.Push(`/static/app.css`, nil))
+	c.Flush()
+}
+
+func TestContextBindFile(t *testing.T) {
+	dir, err := ioutil.TempDir(``, `echo-upload`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	e := New()
+	options := &UploadOptions{MaxSize: 16, AllowedTypes: []string{`text/*`}}
+	e.Post("/upload", func(c Context) error {
+		savedPath, err := c.BindFile(`file`, dir, options)
+		if err != nil {
+			return err
+		}
+		return c.String(filepath.Base(savedPath))
+	})
+	e.RebuildRouter()
+
+	upload := func(filename string, content string) *httptest.ResponseRecorder {
+		body := new(bytes.Buffer)
+		mw := multipart.NewWriter(body)
+		fw, _ := mw.CreateFormFile(`file`, filename)
+		fw.Write([]byte(content))
+		mw.Close()
+		return test.Request(POST, "/upload", e, func(req *http.Request) {
+			req.Body = ioutil.NopCloser(body)
+			req.ContentLength = int64(body.Len())
+			req.Header.Set(HeaderContentType, mw.FormDataContentType())
+		})
+	}
+
+	rec := upload(`notes.txt`, `hello`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `notes.txt`, rec.Body.String())
+	b, _ := ioutil.ReadFile(filepath.Join(dir, `notes.txt`))
+	assert.Equal(t, `hello`, string(b))
+
+	rec = upload(`large.txt`, strings.Repeat(`a`, 17))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+
+	rec = upload(`image.gif`, "GIF89a")
+	assert.Equal(t, http.StatusUnsupportedMediaType, rec.Code)
+
+	rec = upload(`../../etc/pass wd`, `x`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `pass_wd`, rec.Body.String())
+	rec = upload(`..\..\evil.txt`, `x`)
+	assert.Equal(t, `evil.txt`, rec.Body.String())
+	_, err = os.Stat(filepath.Join(dir, `pass_wd`))
+	assert.NoError(t, err)
+
+	// names sanitized to the same name must not overwrite each other
+	rec = upload(`résumé.txt`, `first`)
+	assert.Equal(t, `r_sum_.txt`, rec.Body.String())
+	rec = upload(`rèsumè.txt`, `second`)
+	assert.Equal(t, `r_sum__1.txt`, rec.Body.String())
+	b, _ = ioutil.ReadFile(filepath.Join(dir, `r_sum_.txt`))
+	assert.Equal(t, `first`, string(b))
+	b, _ = ioutil.ReadFile(filepath.Join(dir, `r_sum__1.txt`))
+	assert.Equal(t, `second`, string(b))
+}
+
+func TestSanitizeFileName(t *testing.T) {
+	assert.Equal(t, `passwd`, SanitizeFileName(`../../etc/passwd`))
+	assert.Equal(t, `evil.exe`, SanitizeFileName(`C:\Windows\evil.exe`))
+	assert.Equal(t, `htaccess`, SanitizeFileName(`.htaccess`))
+	assert.Equal(t, `file`, SanitizeFileName(`..`))
+	assert.Equal(t, `a_b.txt`, SanitizeFileName(`a b.txt`))
+}
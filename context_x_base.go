@@ -3,7 +3,10 @@ package echo
 import (
 	"bytes"
 	"context"
+	"crypto/rand"
+	"encoding/base64"
 	"fmt"
+	"net/http"
 	"time"
 
 	"github.com/admpub/events"
@@ -15,6 +18,8 @@ import (
 	"github.com/webx-top/echo/param"
 )
 
+const cspNonceKey = `_cspNonce`
+
 type xContext struct {
 	Validator
 	Translator
@@ -22,6 +27,9 @@ type xContext struct {
 	transaction         *BaseTransaction
 	sessioner           Sessioner
 	cookier             Cookier
+	body                []byte
+	bodyErr             error
+	bodyCached          bool
 	context             context.Context
 	request             engine.Request
 	response            engine.Response
@@ -35,14 +43,17 @@ type xContext struct {
 	handler             Handler
 	route               *Route
 	rid                 int
+	host                *Host
 	echo                *Echo
 	funcs               map[string]interface{}
 	renderer            Renderer
 	sessionOptions      *SessionOptions
 	withFormatExtension bool
 	format              string
+	formatForced        bool
 	code                int
 	preResponseHook     []func() error
+	postResponseHook    []func(Context)
 	dataEngine          Data
 	accept              *Accepts
 	auto                bool
@@ -51,20 +62,21 @@ type xContext struct {
 // NewContext creates a Context object.
 func NewContext(req engine.Request, res engine.Response, e *Echo) Context {
 	c := &xContext{
-		Validator:   e.Validator,
-		Translator:  DefaultNopTranslate,
-		Emitter:     emitter.DefaultCondEmitter,
-		transaction: DefaultNopTransaction,
-		context:     context.Background(),
-		request:     req,
-		response:    res,
-		echo:        e,
-		pvalues:     make([]string, *e.maxParam),
-		internal:    param.NewMap(),
-		store:       make(Store),
-		handler:     NotFoundHandler,
-		funcs:       make(map[string]interface{}),
-		sessioner:   DefaultSession,
+		Validator:           e.Validator,
+		Translator:          DefaultNopTranslate,
+		Emitter:             emitter.DefaultCondEmitter,
+		transaction:         DefaultNopTransaction,
+		context:             context.Background(),
+		request:             req,
+		response:            res,
+		echo:                e,
+		pvalues:             make([]string, *e.maxParam),
+		internal:            param.NewMap(),
+		store:               make(Store),
+		handler:             NotFoundHandler,
+		funcs:               make(map[string]interface{}),
+		sessioner:           DefaultSession,
+		withFormatExtension: e.formatExtension,
 	}
 	c.cookier = NewCookier(c)
 	c.dataEngine = NewData(c)
@@ -75,10 +87,25 @@ func (c *xContext) StdContext() context.Context {
 	return c.context
 }
 
+// Internal returns the request-scoped internal store.
 func (c *xContext) Internal() *param.SafeMap {
 	return c.internal
 }
 
+// CSPNonce returns the request-scoped Content-Security-Policy nonce.
+func (c *xContext) CSPNonce() string {
+	if nonce, ok := c.internal.Load(cspNonceKey); ok {
+		return nonce.(string)
+	}
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	nonce := base64.StdEncoding.EncodeToString(b)
+	c.internal.Set(cspNonceKey, nonce)
+	return nonce
+}
+
 func (c *xContext) SetStdContext(ctx context.Context) {
 	c.context = ctx
 }
@@ -128,7 +155,25 @@ func (c *xContext) SetAuto(on bool) Context {
 }
 
 // Error invokes the registered HTTP error handler. Generally used by middleware.
+// The error of a client which disconnected while the response was written is
+// only logged, a broken pipe or reset connection to an upstream service before
+// the response is handled as any other error.
 func (c *xContext) Error(err error) {
+	if IsClientDisconnect(err) && (c.response.Committed() || c.context.Err() != nil) {
+		c.echo.logger.Debug(`client disconnected: `, err, `: `, c.request.URL().String())
+		return
+	}
+	if c.host != nil {
+		if err == ErrNotFound && c.host.notFoundHandler != nil {
+			if err = c.host.notFoundHandler.Handle(c); err == nil {
+				return
+			}
+		}
+		if c.host.httpErrorHandler != nil {
+			c.host.httpErrorHandler(err, c)
+			return
+		}
+	}
 	c.echo.httpErrorHandler(err, c)
 }
 
@@ -175,6 +220,9 @@ func (c *xContext) Reset(req engine.Request, res engine.Response) {
 	c.transaction = DefaultNopTransaction
 	c.sessioner = DefaultSession
 	c.cookier = NewCookier(c)
+	c.body = nil
+	c.bodyErr = nil
+	c.bodyCached = false
 	c.context = context.Background()
 	c.request = req
 	c.response = res
@@ -189,12 +237,15 @@ func (c *xContext) Reset(req engine.Request, res engine.Response) {
 	c.handler = NotFoundHandler
 	c.route = nil
 	c.rid = -1
+	c.host = nil
 	c.sessionOptions = nil
-	c.withFormatExtension = false
+	c.withFormatExtension = c.echo.formatExtension
 	c.format = ""
+	c.formatForced = false
 	c.code = 0
 	c.auto = false
 	c.preResponseHook = nil
+	c.postResponseHook = nil
 	c.accept = nil
 	c.dataEngine = NewData(c)
 	// NOTE: Don't reset because it has to have length c.echo.maxParam at all times
@@ -260,6 +311,7 @@ func (c *xContext) ToStringSlice(v []string) param.StringSlice {
 
 func (c *xContext) SetFormat(format string) {
 	c.format = format
+	c.formatForced = len(format) > 0
 }
 
 func (c *xContext) WithFormatExtension(on bool) {
@@ -317,6 +369,31 @@ func (c *xContext) preResponse() error {
 	return nil
 }
 
+func (c *xContext) AddPostResponseHook(hook func(Context)) Context {
+	c.postResponseHook = append(c.postResponseHook, hook)
+	return c
+}
+
+func (c *xContext) postResponse() {
+	for _, hook := range c.postResponseHook {
+		c.runPostResponseHook(hook)
+	}
+}
+
+// runPostResponseHook runs the hook, a panic is logged so that the next hooks
+// still run and the context is still returned to the pool.
+func (c *xContext) runPostResponseHook(hook func(Context)) {
+	defer func() {
+		if r := recover(); r != nil {
+			if r == http.ErrAbortHandler {
+				panic(r)
+			}
+			c.echo.logger.Error(NewPanicError(r, nil, c.echo.debug).Parse())
+		}
+	}()
+	hook(c)
+}
+
 func (c *xContext) PrintFuncs() {
 	for key, fn := range c.Funcs() {
 		fmt.Printf("[Template Func](%p) %-15s -> %s \n", fn, key, HandlerName(fn))
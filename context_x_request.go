@@ -1,8 +1,14 @@
 package echo
 
 import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"fmt"
 	"io"
+	"io/ioutil"
 	"mime/multipart"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -22,6 +28,14 @@ func (c *xContext) Path() string {
 	return c.path
 }
 
+// FullPath is an alias of `Path` which returns an empty string when no route matched.
+func (c *xContext) FullPath() string {
+	if c.rid < 0 {
+		return ``
+	}
+	return c.Path()
+}
+
 // P returns path parameter by index.
 func (c *xContext) P(i int, defaults ...string) (value string) {
 	l := len(c.pnames)
@@ -125,6 +139,11 @@ func (c *xContext) Query(name string, defaults ...string) (value string) {
 	return
 }
 
+// QueryString returns the raw query string without the leading `?`.
+func (c *xContext) QueryString() string {
+	return c.request.URL().RawQuery()
+}
+
 func (c *xContext) Queryx(name string, defaults ...string) param.String {
 	return param.String(c.Query(name, defaults...))
 }
@@ -176,6 +195,182 @@ func (c *xContext) MustBind(i interface{}, filter ...FormDataFilter) error {
 	return c.echo.binder.MustBind(i, c, filter...)
 }
 
+func (c *xContext) BindNDJSON(fn func(decode func(interface{}) error) error) error {
+	var body io.ReadCloser
+	if c.bodyCached {
+		body = c.RequestBody()
+	} else {
+		if err := DecodeRequestBody(c); err != nil {
+			return err
+		}
+		// the records are read from the request body as they come in, not buffered
+		if body = c.request.Body(); body == nil {
+			body = ioutil.NopCloser(bytes.NewReader(nil))
+		}
+	}
+	defer body.Close()
+	limit := c.echo.MaxRequestBodySize()
+	maxLine := int(limit)
+	if maxLine <= 0 {
+		maxLine = DefaultNDJSONMaxLineSize
+	}
+	var reader io.Reader = body
+	if limit > 0 {
+		reader = &bodyLimitReader{Reader: body, remaining: limit}
+	}
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(nil, maxLine)
+	options := c.echo.JSONDecodeOptions()
+	var line int
+	return fn(func(i interface{}) error {
+		for scanner.Scan() {
+			line++
+			b := bytes.TrimSpace(scanner.Bytes())
+			if len(b) == 0 {
+				continue
+			}
+			if err := bindJSON(i, bytes.NewReader(b), options); err != nil {
+				return NewHTTPError(http.StatusBadRequest, fmt.Sprintf(`invalid NDJSON record at line %d: %v`, line, err))
+			}
+			return nil
+		}
+		switch err := scanner.Err(); err {
+		case nil:
+			return io.EOF
+		case bufio.ErrTooLong:
+			return ErrStatusRequestEntityTooLarge
+		default:
+			return err
+		}
+	})
+}
+
+func (c *xContext) RequestBody() io.ReadCloser {
+	if !c.bodyCached {
+		c.bodyCached = true
+		if c.bodyErr = DecodeRequestBody(c); c.bodyErr != nil {
+			return ioutil.NopCloser(&errReader{err: c.bodyErr})
+		}
+		if body := c.request.Body(); body != nil {
+			limit := c.echo.MaxRequestBodySize()
+			if limit <= 0 {
+				limit = DefaultRequestBodyCacheSize
+			}
+			if limit > 0 {
+				c.body, c.bodyErr = ioutil.ReadAll(io.LimitReader(body, limit+1))
+				if c.bodyErr == nil && int64(len(c.body)) > limit {
+					c.bodyErr = ErrStatusRequestEntityTooLarge
+				}
+			} else {
+				// the `BodyLimit` middleware reports an oversized body as a read error
+				c.body, c.bodyErr = ioutil.ReadAll(body)
+			}
+			body.Close()
+		}
+		if c.bodyErr != nil {
+			c.body = nil
+			c.request.SetBody(&errReader{err: c.bodyErr})
+		} else {
+			c.request.SetBody(bytes.NewReader(c.body))
+		}
+	}
+	if c.bodyErr != nil {
+		return ioutil.NopCloser(&errReader{err: c.bodyErr})
+	}
+	return ioutil.NopCloser(bytes.NewReader(c.body))
+}
+
+// bodyLimitReader fails with ErrStatusRequestEntityTooLarge once more than
+// the limit has been read.
+type bodyLimitReader struct {
+	io.Reader
+	remaining int64
+}
+
+func (r *bodyLimitReader) Read(p []byte) (int, error) {
+	if r.remaining <= 0 {
+		var b [1]byte
+		n, err := r.Reader.Read(b[:])
+		if n > 0 {
+			return 0, ErrStatusRequestEntityTooLarge
+		}
+		return 0, err
+	}
+	if int64(len(p)) > r.remaining {
+		p = p[:r.remaining]
+	}
+	n, err := r.Reader.Read(p)
+	r.remaining -= int64(n)
+	return n, err
+}
+
+type errReader struct {
+	err error
+}
+
+func (r *errReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
+
+func (c *xContext) BindJSON(i interface{}) error {
+	return c.bindBody(func(body io.Reader) error {
+		return bindJSON(i, body, c.echo.JSONDecodeOptions())
+	})
+}
+
+func (c *xContext) BindXML(i interface{}) error {
+	return c.bindBody(func(body io.Reader) error {
+		return xml.NewDecoder(body).Decode(i)
+	})
+}
+
+// bindBody decodes the request body, which is limited like `Context.RequestBody`,
+// regardless of the `Content-Type` header.
+func (c *xContext) bindBody(decode func(io.Reader) error) error {
+	if err := DecodeRequestBody(c); err != nil {
+		return err
+	}
+	body := c.RequestBody()
+	defer body.Close()
+	err := decode(body)
+	if err == nil || err == ErrStatusRequestEntityTooLarge {
+		return err
+	}
+	return NewHTTPError(http.StatusBadRequest, err.Error())
+}
+
+func (c *xContext) BindParams(i interface{}) error {
+	return BindParams(i, c)
+}
+
+func (c *xContext) BindHeaders(i interface{}) error {
+	return BindHeaders(i, c)
+}
+
+func (c *xContext) BindQuery(i interface{}) error {
+	return BindQuery(i, c)
+}
+
+func (c *xContext) BindPartial(i interface{}, filter ...FormDataFilter) (FieldSet, error) {
+	return BindPartial(i, c, filter...)
+}
+
+func (c *xContext) BindAndValidate(i interface{}, filter ...FormDataFilter) error {
+	if err := c.MustBind(i, filter...); err != nil {
+		return err
+	}
+	if c.echo.bindHook != nil {
+		if err := c.echo.bindHook(i, c); err != nil {
+			return err
+		}
+	}
+	result := c.Validate(i)
+	if result.Ok() {
+		return nil
+	}
+	return NewValidateErrors(result)
+}
+
 func (c *xContext) Header(name string) string {
 	return c.Request().Header().Get(name)
 }
@@ -277,9 +472,8 @@ func (c *xContext) ResolveFormat() string {
 		return format
 	}
 	if c.withFormatExtension {
-		urlPath := c.Request().URL().Path()
-		if pos := strings.LastIndex(urlPath, `.`); pos > -1 {
-			return strings.ToLower(urlPath[pos+1:])
+		if format, pos := formatExtension(c.Request().URL().Path()); pos > -1 {
+			return format
 		}
 	}
 
@@ -295,6 +489,16 @@ func (c *xContext) ResolveFormat() string {
 	return `html`
 }
 
+// formatExtension returns the lowercase extension of the last path segment
+// and its position, e.g. `json` of `/users/42.json`, or -1 if there is none.
+func formatExtension(urlPath string) (string, int) {
+	pos := strings.LastIndexByte(urlPath, '.')
+	if pos < 0 || strings.IndexByte(urlPath[pos:], '/') > -1 {
+		return ``, -1
+	}
+	return strings.ToLower(urlPath[pos+1:]), pos
+}
+
 func (c *xContext) Accept() *Accepts {
 	if c.accept != nil {
 		return c.accept
@@ -306,6 +510,38 @@ func (c *xContext) Accept() *Accepts {
 	return c.accept.Simple(3)
 }
 
+func (c *xContext) Accepts(formats ...string) string {
+	if len(formats) == 0 {
+		return ``
+	}
+	if c.formatForced {
+		for _, format := range formats {
+			if format == c.format {
+				return format
+			}
+		}
+		return ``
+	}
+	if len(c.Header(HeaderAccept)) == 0 {
+		return formats[0]
+	}
+	accepted := map[string]bool{}
+	for _, accept := range c.Accept().Type {
+		if accept.Mime == `*/*` {
+			return formats[0]
+		}
+		if format, ok := c.echo.acceptFormats[accept.Mime]; ok {
+			accepted[format] = true
+		}
+	}
+	for _, format := range formats {
+		if accepted[format] {
+			return format
+		}
+	}
+	return ``
+}
+
 // Protocol returns request protocol name, such as HTTP/1.1 .
 func (c *xContext) Protocol() string {
 	return c.Request().Proto()
@@ -437,6 +673,43 @@ func (c *xContext) SaveUploadedFile(fieldName string, saveAbsPath string, saveFi
 	return fileHdr, nil
 }
 
+func (c *xContext) BindFile(fieldName string, dstDir string, options ...*UploadOptions) (string, error) {
+	opts := &UploadOptions{}
+	if len(options) > 0 && options[0] != nil {
+		opts = options[0]
+	}
+	fileSrc, fileHdr, err := c.Request().FormFile(fieldName)
+	if err != nil {
+		return ``, err
+	}
+	defer fileSrc.Close()
+	if opts.MaxSize > 0 && fileHdr.Size > opts.MaxSize {
+		return ``, ErrUploadTooLarge
+	}
+	head := make([]byte, 512)
+	n, err := io.ReadFull(fileSrc, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return ``, err
+	}
+	head = head[:n]
+	if !opts.allowType(http.DetectContentType(head)) {
+		return ``, ErrUnsupportedMediaType
+	}
+	fileDst, err := createUniqueFile(dstDir, SanitizeFileName(fileHdr.Filename))
+	if err != nil {
+		return ``, err
+	}
+	defer fileDst.Close()
+	if _, err = fileDst.Write(head); err == nil {
+		_, err = io.Copy(fileDst, fileSrc)
+	}
+	if err != nil {
+		os.Remove(fileDst.Name())
+		return ``, err
+	}
+	return fileDst.Name(), nil
+}
+
 func (c *xContext) SaveUploadedFileToWriter(fieldName string, writer io.Writer) (*multipart.FileHeader, error) {
 	fileSrc, fileHdr, err := c.Request().FormFile(fieldName)
 	if err != nil {
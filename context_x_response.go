@@ -2,11 +2,14 @@ package echo
 
 import (
 	"bytes"
+	stdjson "encoding/json"
 	"encoding/xml"
+	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
 	"time"
 	"unicode"
 
@@ -14,11 +17,36 @@ import (
 	"github.com/webx-top/echo/engine"
 )
 
+// blobChunkSize is the size of the chunks written by `Blob`.
+const blobChunkSize = 32 << 10 // 32 KB
+
 // Response returns *Response.
 func (c *xContext) Response() engine.Response {
 	return c.response
 }
 
+// mergeStored merges the context store into the map data, keeping the values of data.
+func (c *xContext) mergeStored(data interface{}) interface{} {
+	var m map[string]interface{}
+	switch v := data.(type) {
+	case nil:
+	case Store:
+		m = v
+	case map[string]interface{}:
+		m = v
+	default:
+		return data
+	}
+	merged := make(Store, len(c.store)+len(m))
+	for key, value := range c.store {
+		merged[key] = value
+	}
+	for key, value := range m {
+		merged[key] = value
+	}
+	return merged
+}
+
 // Render renders a template with data and sends a text/html response with status
 // code. Templates can be registered using `Echo.SetRenderer()`.
 func (c *xContext) Render(name string, data interface{}, codes ...int) (err error) {
@@ -43,30 +71,129 @@ func (c *xContext) Render(name string, data interface{}, codes ...int) (err erro
 	if data == nil {
 		data = c.dataEngine.GetData()
 	}
+	if c.echo.autoTemplateData {
+		data = c.mergeStored(data)
+	}
+	if err = c.context.Err(); err != nil {
+		return
+	}
+	if c.echo.renderFlushSize > 0 {
+		return c.renderStream(name, data, codes...)
+	}
 	b, err := c.Fetch(name, data)
 	if err != nil {
 		return
 	}
 	b = bytes.TrimLeftFunc(b, unicode.IsSpace)
-	c.response.Header().Set(HeaderContentType, MIMETextHTMLCharsetUTF8)
+	c.response.Header().Set(HeaderContentType, c.echo.ContentType(MIMETextHTML))
 	err = c.Blob(b, codes...)
 	return
 }
 
+// renderStream writes the rendered template directly to the response, which
+// is flushed every time `Echo.RenderFlushSize` bytes have been written. The
+// response is committed by the first write: an error of the renderer after it
+// is still returned, but the error handler can only log it and the client
+// receives the output truncated where the error occurred.
+func (c *xContext) renderStream(name string, data interface{}, codes ...int) error {
+	if c.renderer == nil {
+		if c.echo.renderer == nil {
+			return ErrRendererNotRegistered
+		}
+		c.renderer = c.echo.renderer
+	}
+	if len(codes) > 0 {
+		c.code = codes[0]
+	}
+	if c.code == 0 {
+		c.code = http.StatusOK
+	}
+	w := &renderWriter{c: c, flushSize: c.echo.renderFlushSize}
+	if err := c.renderer.Render(w, name, data, c); err != nil {
+		return err
+	}
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+	w.flush()
+	return nil
+}
+
+// renderWriter commits the response on the first write and flushes it
+// periodically. The leading whitespace of the output is skipped.
+type renderWriter struct {
+	c         *xContext
+	flushSize int
+	pending   int
+	started   bool
+	committed bool
+}
+
+func (w *renderWriter) writeHeader() error {
+	if w.committed {
+		return nil
+	}
+	w.committed = true
+	if err := w.c.preResponse(); err != nil {
+		return err
+	}
+	w.c.response.Header().Set(HeaderContentType, w.c.echo.ContentType(MIMETextHTML))
+	w.c.response.WriteHeader(w.c.code)
+	return nil
+}
+
+func (w *renderWriter) Write(b []byte) (int, error) {
+	n := len(b)
+	if !w.started {
+		b = bytes.TrimLeftFunc(b, unicode.IsSpace)
+		if len(b) == 0 {
+			return n, nil
+		}
+		w.started = true
+	}
+	if err := w.writeHeader(); err != nil {
+		return 0, err
+	}
+	if err := w.c.context.Err(); err != nil {
+		return 0, err
+	}
+	if _, err := w.c.response.Write(b); err != nil {
+		return 0, err
+	}
+	w.pending += len(b)
+	if w.pending >= w.flushSize {
+		w.pending = 0
+		w.flush()
+	}
+	return n, nil
+}
+
+// flush flushes the writer of the response first, so that the output buffered
+// by a compressing writer (see `middleware.Compress`) is sent too.
+func (w *renderWriter) flush() {
+	if flusher, ok := w.c.response.Writer().(http.Flusher); ok {
+		flusher.Flush()
+		return
+	}
+	w.c.Flush()
+}
+
 // HTML sends an HTTP response with status code.
 func (c *xContext) HTML(html string, codes ...int) (err error) {
-	c.response.Header().Set(HeaderContentType, MIMETextHTMLCharsetUTF8)
+	c.response.Header().Set(HeaderContentType, c.echo.ContentType(MIMETextHTML))
 	err = c.Blob([]byte(html), codes...)
 	return
 }
 
 // String sends a string response with status code.
 func (c *xContext) String(s string, codes ...int) (err error) {
-	c.response.Header().Set(HeaderContentType, MIMETextPlainCharsetUTF8)
+	c.response.Header().Set(HeaderContentType, c.echo.ContentType(MIMETextPlain))
 	err = c.Blob([]byte(s), codes...)
 	return
 }
 
+// Blob sends a blob response with status code. The body is written in chunks
+// and writing stops with the context error once the standard context is done.
 func (c *xContext) Blob(b []byte, codes ...int) (err error) {
 	if len(codes) > 0 {
 		c.code = codes[0]
@@ -78,19 +205,123 @@ func (c *xContext) Blob(b []byte, codes ...int) (err error) {
 	if err != nil {
 		return
 	}
+	if err = c.context.Err(); err != nil {
+		return
+	}
 	c.response.WriteHeader(c.code)
-	_, err = c.response.Write(b)
+	for len(b) > 0 {
+		if err = c.context.Err(); err != nil {
+			return
+		}
+		n := len(b)
+		if n > blobChunkSize {
+			n = blobChunkSize
+		}
+		if _, err = c.response.Write(b[:n]); err != nil {
+			return
+		}
+		b = b[n:]
+	}
+	return
+}
+
+// marshal calls fn and reports its failure, including a panic, as a plain
+// error: the default error handler answers it with a 500 and only shows its
+// detail in debug mode, it is always logged.
+func marshal(format string, fn func() ([]byte, error)) (b []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf(`failed to marshal %s response: %v`, format, r)
+		}
+	}()
+	b, err = fn()
+	if err != nil {
+		err = fmt.Errorf(`failed to marshal %s response: %v`, format, err)
+	}
 	return
 }
 
 // JSON sends a JSON response with status code.
 func (c *xContext) JSON(i interface{}, codes ...int) (err error) {
-	var b []byte
-	if c.echo.Debug() {
-		b, err = json.MarshalIndent(i, "", "  ")
+	if limit := c.echo.jsonLengthLimit; limit > 0 && !c.echo.Debug() {
+		return c.jsonStream(i, limit, codes...)
+	}
+	b, err := marshal(`JSON`, func() ([]byte, error) {
+		if c.echo.Debug() {
+			return json.MarshalIndent(i, "", "  ")
+		}
+		return json.Marshal(i)
+	})
+	if err != nil {
+		return err
+	}
+	return c.JSONBlob(b, codes...)
+}
+
+// jsonStream encodes i into a buffer of limit bytes and sends it with the
+// `Content-Length` header. A larger body is streamed to the response instead.
+func (c *xContext) jsonStream(i interface{}, limit int, codes ...int) error {
+	w := &jsonWriter{c: c, limit: limit, codes: codes}
+	_, err := marshal(`JSON`, func() ([]byte, error) {
+		return nil, json.NewEncoder(w).Encode(i)
+	})
+	if w.err != nil {
+		return w.err
+	}
+	if err != nil || w.committed {
+		return err
+	}
+	return c.JSONBlob(bytes.TrimSuffix(w.buf.Bytes(), []byte("\n")), codes...)
+}
+
+// jsonWriter buffers up to limit bytes, then commits the response and writes
+// through to it.
+type jsonWriter struct {
+	c         *xContext
+	limit     int
+	codes     []int
+	buf       bytes.Buffer
+	committed bool
+	err       error
+}
+
+func (w *jsonWriter) Write(b []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	if w.committed {
+		_, w.err = w.c.response.Write(b)
+	} else if w.buf.Len()+len(b) <= w.limit {
+		return w.buf.Write(b)
 	} else {
-		b, err = json.Marshal(i)
+		w.committed = true
+		w.c.response.Header().Set(HeaderContentType, w.c.echo.ContentType(MIMEApplicationJSON))
+		if w.err = w.c.Blob(w.buf.Bytes(), w.codes...); w.err == nil {
+			_, w.err = w.c.response.Write(b)
+		}
+	}
+	if w.err != nil {
+		return 0, w.err
 	}
+	return len(b), nil
+}
+
+// JSONOmitEmpty sends a JSON response with status code, omitting the null and
+// empty values of the objects recursively. See `OmitEmptyJSON`.
+func (c *xContext) JSONOmitEmpty(i interface{}, codes ...int) (err error) {
+	b, err := marshal(`JSON`, func() ([]byte, error) {
+		b, err := json.Marshal(i)
+		if err != nil {
+			return nil, err
+		}
+		b, err = OmitEmptyJSON(b)
+		if err != nil || !c.echo.Debug() {
+			return b, err
+		}
+		buf := new(bytes.Buffer)
+		err = stdjson.Indent(buf, b, "", "  ")
+		return buf.Bytes(), err
+	})
 	if err != nil {
 		return err
 	}
@@ -99,7 +330,11 @@ func (c *xContext) JSON(i interface{}, codes ...int) (err error) {
 
 // JSONBlob sends a JSON blob response with status code.
 func (c *xContext) JSONBlob(b []byte, codes ...int) (err error) {
-	c.response.Header().Set(HeaderContentType, MIMEApplicationJSONCharsetUTF8)
+	c.response.Header().Set(HeaderContentType, c.echo.ContentType(MIMEApplicationJSON))
+	if limit := c.echo.jsonLengthLimit; limit > 0 && len(b) <= limit &&
+		len(c.response.Header().Get(HeaderContentEncoding)) == 0 {
+		c.response.Header().Set(HeaderContentLength, strconv.Itoa(len(b)))
+	}
 	err = c.Blob(b, codes...)
 	return
 }
@@ -107,11 +342,13 @@ func (c *xContext) JSONBlob(b []byte, codes ...int) (err error) {
 // JSONP sends a JSONP response with status code. It uses `callback` to construct
 // the JSONP payload.
 func (c *xContext) JSONP(callback string, i interface{}, codes ...int) (err error) {
-	b, err := json.Marshal(i)
+	b, err := marshal(`JSONP`, func() ([]byte, error) {
+		return json.Marshal(i)
+	})
 	if err != nil {
 		return err
 	}
-	c.response.Header().Set(HeaderContentType, MIMEApplicationJavaScriptCharsetUTF8)
+	c.response.Header().Set(HeaderContentType, c.echo.ContentType(MIMEApplicationJavaScript))
 	b = []byte(callback + "(" + string(b) + ");")
 	err = c.Blob(b, codes...)
 	return
@@ -119,12 +356,12 @@ func (c *xContext) JSONP(callback string, i interface{}, codes ...int) (err erro
 
 // XML sends an XML response with status code.
 func (c *xContext) XML(i interface{}, codes ...int) (err error) {
-	var b []byte
-	if c.echo.Debug() {
-		b, err = xml.MarshalIndent(i, "", "  ")
-	} else {
-		b, err = xml.Marshal(i)
-	}
+	b, err := marshal(`XML`, func() ([]byte, error) {
+		if c.echo.Debug() {
+			return xml.MarshalIndent(i, "", "  ")
+		}
+		return xml.Marshal(i)
+	})
 	if err != nil {
 		return err
 	}
@@ -133,7 +370,7 @@ func (c *xContext) XML(i interface{}, codes ...int) (err error) {
 
 // XMLBlob sends a XML blob response with status code.
 func (c *xContext) XMLBlob(b []byte, codes ...int) (err error) {
-	c.response.Header().Set(HeaderContentType, MIMEApplicationXMLCharsetUTF8)
+	c.response.Header().Set(HeaderContentType, c.echo.ContentType(MIMEApplicationXML))
 	b = []byte(xml.Header + string(b))
 	err = c.Blob(b, codes...)
 	return
@@ -143,6 +380,64 @@ func (c *xContext) Stream(step func(w io.Writer) bool) {
 	c.response.Stream(step)
 }
 
+func (c *xContext) Push(target string, opts *http.PushOptions) error {
+	if pusher, ok := c.response.(http.Pusher); ok {
+		return pusher.Push(target, opts)
+	}
+	return http.ErrNotSupported
+}
+
+func (c *xContext) Flush() {
+	if flusher, ok := c.response.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (c *xContext) AddVary(names ...string) {
+	AddVary(c.response.Header(), names...)
+}
+
+func (c *xContext) CacheControl(directives ...string) {
+	hdr := c.response.Header()
+	if value := MergeCacheControl(hdr.Get(HeaderCacheControl), directives...); len(value) > 0 {
+		hdr.Set(HeaderCacheControl, value)
+	}
+}
+
+func (c *xContext) NoStore() {
+	c.CacheControl(`no-store`)
+}
+
+func (c *xContext) MaxAge(d time.Duration) {
+	c.CacheControl(`max-age=` + strconv.FormatInt(int64(d/time.Second), 10))
+}
+
+func (c *xContext) Private() {
+	c.CacheControl(`private`)
+}
+
+func (c *xContext) Public() {
+	c.CacheControl(`public`)
+}
+
+func (c *xContext) OnWrite(fn func(n int)) {
+	c.response.SetWriter(&writeObserver{Writer: c.response.Writer(), fn: fn})
+}
+
+// writeObserver reports the number of bytes written to the wrapped writer.
+type writeObserver struct {
+	io.Writer
+	fn func(n int)
+}
+
+func (w *writeObserver) Write(b []byte) (n int, err error) {
+	n, err = w.Writer.Write(b)
+	if n > 0 {
+		w.fn(n)
+	}
+	return
+}
+
 func (c *xContext) SSEvent(event string, data chan interface{}) (err error) {
 	hdr := c.response.Header()
 	hdr.Set(HeaderContentType, MIMEEventStream)
@@ -180,14 +475,19 @@ func (c *xContext) Attachment(r io.Reader, name string, inline ...bool) (err err
 	return
 }
 
+// File sends the file. When the client accepts it, a precompressed variant
+// of the file (e.g. `app.js.br` or `app.js.gz` for `app.js`) is sent instead
+// with the corresponding `Content-Encoding`, unless the response is already
+// encoded, e.g. by the compress middleware.
 func (c *xContext) File(file string, fs ...http.FileSystem) (err error) {
 	var f http.File
-	customFS := len(fs) > 0 && fs[0] != nil
-	if customFS {
-		f, err = fs[0].Open(file)
-	} else {
-		f, err = os.Open(file)
+	open := func(name string) (http.File, error) {
+		return os.Open(name)
+	}
+	if len(fs) > 0 && fs[0] != nil {
+		open = fs[0].Open
 	}
+	f, err = open(file)
 	if err != nil {
 		return ErrNotFound
 	}
@@ -196,25 +496,97 @@ func (c *xContext) File(file string, fs ...http.FileSystem) (err error) {
 	fi, _ := f.Stat()
 	if fi.IsDir() {
 		file = filepath.Join(file, "index.html")
-		if customFS {
-			f, err = fs[0].Open(file)
-		} else {
-			f, err = os.Open(file)
-		}
+		f, err = open(file)
 		if err != nil {
 			return ErrNotFound
 		}
+		defer f.Close()
 		fi, _ = f.Stat()
 	}
-	return c.ServeContent(f, fi.Name(), fi.ModTime())
+	name := fi.Name()
+	if len(c.response.Header().Get(HeaderContentEncoding)) > 0 {
+		return c.ServeContent(f, name, fi.ModTime())
+	}
+	var (
+		encodings []string
+		variants  = map[string]http.File{}
+	)
+	for _, p := range PrecompressedEncodings {
+		cf, err := open(file + p.Extension)
+		if err != nil {
+			continue
+		}
+		defer cf.Close()
+		cfi, err := cf.Stat()
+		if err != nil || cfi.IsDir() {
+			continue
+		}
+		encodings = append(encodings, p.Encoding)
+		variants[p.Encoding] = cf
+	}
+	if len(encodings) == 0 {
+		return c.ServeContent(f, name, fi.ModTime())
+	}
+	// the response depends on Accept-Encoding once a variant exists
+	c.AddVary(HeaderAcceptEncoding)
+	encoding := NegotiateEncoding(c.request.Header().Get(HeaderAcceptEncoding), encodings)
+	if len(encoding) == 0 {
+		return c.ServeContent(f, name, fi.ModTime())
+	}
+	cf := variants[encoding]
+	cfi, _ := cf.Stat()
+	c.response.Header().Set(HeaderContentEncoding, encoding)
+	return c.ServeContent(cf, name, cfi.ModTime())
 }
 
 func (c *xContext) ServeContent(content io.Reader, name string, modtime time.Time) error {
+	if rs, ok := content.(io.ReadSeeker); ok {
+		if _, err := rs.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		c.response.Header().Set(HeaderContentType, ContentTypeByExtension(name))
+		// http.ServeContent does not return the errors, they are recorded instead
+		r := &contentReader{ReadSeeker: rs}
+		w := &contentWriter{ResponseWriter: c.response.StdResponseWriter()}
+		http.ServeContent(w, c.request.StdRequest(), name, modtime, r)
+		if r.err != nil {
+			return r.err
+		}
+		return w.err
+	}
 	return c.ServeCallbackContent(func(_ Context) (io.Reader, error) {
 		return content, nil
 	}, name, modtime)
 }
 
+// contentReader records the first read error of the content.
+type contentReader struct {
+	io.ReadSeeker
+	err error
+}
+
+func (r *contentReader) Read(b []byte) (int, error) {
+	n, err := r.ReadSeeker.Read(b)
+	if err != nil && err != io.EOF && r.err == nil {
+		r.err = err
+	}
+	return n, err
+}
+
+// contentWriter records the first write error of the response.
+type contentWriter struct {
+	http.ResponseWriter
+	err error
+}
+
+func (w *contentWriter) Write(b []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(b)
+	if err != nil && w.err == nil {
+		w.err = err
+	}
+	return n, err
+}
+
 func (c *xContext) ServeCallbackContent(callback func(Context) (io.Reader, error), name string, modtime time.Time) error {
 	rq := c.Request()
 	rs := c.Response()
@@ -254,7 +626,7 @@ func (c *xContext) Redirect(url string, codes ...int) error {
 	if len(codes) > 0 {
 		code = codes[0]
 	}
-	if code < http.StatusMultipleChoices || code > http.StatusTemporaryRedirect {
+	if code < http.StatusMultipleChoices || code > http.StatusPermanentRedirect {
 		return ErrInvalidRedirectCode
 	}
 	err := c.preResponse()
@@ -1,5 +1,7 @@
 package echo
 
+import "net/http"
+
 func (c *xContext) Session() Sessioner {
 	return c.sessioner
 }
@@ -42,6 +44,10 @@ func (c *xContext) GetCookie(key string) string {
 	return c.cookier.Get(key)
 }
 
+func (c *xContext) Cookies() []*http.Cookie {
+	return c.cookier.All()
+}
+
 func (c *xContext) SetCookie(key string, val string, args ...interface{}) {
 	c.cookier.Set(key, val, args...)
 }
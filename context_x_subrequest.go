@@ -0,0 +1,80 @@
+package echo
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+
+	"github.com/webx-top/echo/engine"
+)
+
+// Response is the response captured by `Context#SubRequest()`.
+type Response struct {
+	Code   int
+	Header http.Header
+	Body   []byte
+}
+
+type subResponseWriter struct {
+	header http.Header
+	code   int
+	body   *bytes.Buffer
+}
+
+func newSubResponseWriter() *subResponseWriter {
+	return &subResponseWriter{
+		header: http.Header{},
+		body:   new(bytes.Buffer),
+	}
+}
+
+func (w *subResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *subResponseWriter) Write(b []byte) (int, error) {
+	if w.code == 0 {
+		w.code = http.StatusOK
+	}
+	return w.body.Write(b)
+}
+
+func (w *subResponseWriter) WriteHeader(code int) {
+	if w.code == 0 {
+		w.code = code
+	}
+}
+
+// SubRequest invokes another registered route without a network round-trip.
+// The host and the `DefaultSubRequestHeaders` of the current request are copied
+// to the sub request.
+func (c *xContext) SubRequest(method string, path string, body io.Reader) (*Response, error) {
+	req, err := http.NewRequest(method, path, body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(c)
+	parent := c.Request()
+	req.Host = parent.Host()
+	for _, key := range DefaultSubRequestHeaders {
+		if value := parent.Header().Get(key); len(value) > 0 {
+			req.Header.Set(key, value)
+		}
+	}
+	req.RemoteAddr = parent.RemoteAddress()
+	w := newSubResponseWriter()
+	ereq, eres, err := engine.WrapStd(req, w)
+	if err != nil {
+		return nil, err
+	}
+	c.echo.ServeHTTP(ereq, eres)
+	code := w.code
+	if code == 0 {
+		code = http.StatusOK
+	}
+	return &Response{
+		Code:   code,
+		Header: w.header,
+		Body:   w.body.Bytes(),
+	}, nil
+}
@@ -66,6 +66,9 @@ func (c *CookieOptions) SetMaxAge(maxAge int) *CookieOptions {
 type Cookier interface {
 	Get(key string) string
 	Set(key string, val string, args ...interface{}) Cookier
+	// All returns all cookies of the request, parsed once. The values are
+	// unescaped like the ones returned by `Get`.
+	All() []*http.Cookie
 }
 
 //NewCookier create a cookie instance
@@ -161,6 +164,7 @@ func (c *Cookie) Send(ctx Context) {
 type cookie struct {
 	context Context
 	cookies []*Cookie
+	all     []*http.Cookie
 }
 
 func (c *cookie) Get(key string) string {
@@ -171,6 +175,23 @@ func (c *cookie) Get(key string) string {
 	return val
 }
 
+func (c *cookie) All() []*http.Cookie {
+	if c.all != nil {
+		return c.all
+	}
+	req := &http.Request{Header: http.Header{}}
+	if v := c.context.Request().Header().Get(HeaderCookie); len(v) > 0 {
+		req.Header.Set(HeaderCookie, v)
+	}
+	c.all = req.Cookies()
+	for _, v := range c.all {
+		if val, err := url.QueryUnescape(v.Value); err == nil {
+			v.Value = val
+		}
+	}
+	return c.all
+}
+
 // Set Set cookie value
 // @param string key
 // @param string value
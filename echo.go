@@ -2,14 +2,20 @@ package echo
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 
 	"github.com/admpub/log"
 
+	"github.com/webx-top/echo/encoding/json"
 	"github.com/webx-top/echo/engine"
 	"github.com/webx-top/echo/logger"
 )
@@ -32,10 +38,13 @@ type (
 		router            *Router
 		logger            logger.Logger
 		groups            map[string]*Group
+		groupDefaults     []interface{}
 		handlerWrapper    []func(interface{}) Handler
 		middlewareWrapper []func(interface{}) Middleware
 		acceptFormats     map[string]string //mime=>format
 		formatRenderers   map[string]func(ctx Context, data interface{}) error
+		errorTemplates    map[int]string //code=>template
+		requestDecoders   map[string]func(io.Reader) (io.Reader, error)
 		FuncMap           map[string]interface{}
 		RouteDebug        bool
 		MiddlewareDebug   bool
@@ -43,6 +52,22 @@ type (
 		Validator         Validator
 		FormSliceMaxIndex int
 		parseHeaderAccept bool
+		autoTemplateData  bool
+		defaultCharset    string
+		jsonDecodeOptions JSONDecodeOptions
+		problemJSON       bool
+		jsonLengthLimit   int
+		defaultHeaders    map[string]string
+		bindHook          func(interface{}, Context) error
+		routeConflict     RouteConflictPolicy
+		formatExtension   bool
+		renderFlushSize   int
+		requireResponse   bool
+		shutdownCtx       context.Context
+		shutdownCancel    context.CancelFunc
+
+		// FormSliceMaxLength is the maximum number of the repeated values bound to a slice field
+		FormSliceMaxLength int
 	}
 
 	Middleware interface {
@@ -69,7 +94,8 @@ type (
 
 	HandlerFunc func(Context) error
 
-	// HTTPErrorHandler is a centralized HTTP error handler.
+	// HTTPErrorHandler is a centralized HTTP error handler. It receives the
+	// context of the failed request, with the values set via `Context.Set`.
 	HTTPErrorHandler func(error, Context)
 
 	// Renderer is the interface that wraps the Render method.
@@ -130,35 +156,186 @@ func (e *Echo) Reset() *Echo {
 	e.router = NewRouter(e)
 	e.logger = log.GetLogger("echo")
 	e.groups = make(map[string]*Group)
+	e.groupDefaults = nil
 	e.handlerWrapper = []func(interface{}) Handler{}
 	e.middlewareWrapper = []func(interface{}) Middleware{}
 	e.acceptFormats = DefaultAcceptFormats
 	e.formatRenderers = DefaultFormatRenderers
+	e.errorTemplates = make(map[int]string)
+	e.SetRequestDecoders(DefaultRequestDecoders)
 	e.FuncMap = make(map[string]interface{})
 	e.RouteDebug = false
 	e.MiddlewareDebug = false
 	e.JSONPVarName = `callback`
 	e.Validator = DefaultNopValidate
 	e.FormSliceMaxIndex = 100
+	e.FormSliceMaxLength = 1000
 	e.parseHeaderAccept = false
+	e.defaultCharset = `utf-8`
+	e.jsonDecodeOptions = JSONDecodeOptions{}
+	e.problemJSON = false
+	e.jsonLengthLimit = 0
+	e.defaultHeaders = make(map[string]string)
+	e.resetShutdown()
 	return e
 }
 
+// resetShutdown creates the context from which the context of each request is
+// derived, cancelled as soon as `Shutdown` is called.
+func (e *Echo) resetShutdown() {
+	e.shutdownCtx, e.shutdownCancel = context.WithCancel(context.Background())
+}
+
+// ShutdownSignal returns a channel closed as soon as `Shutdown` is called,
+// the same one as `Context#Done()` of the requests in flight at that time.
+func (e *Echo) ShutdownSignal() <-chan struct{} {
+	return e.shutdownCtx.Done()
+}
+
 func (e *Echo) ParseHeaderAccept(on bool) *Echo {
 	e.parseHeaderAccept = on
 	return e
 }
 
+// UseProblemJSON makes `DefaultHTTPErrorHandler` respond with RFC 7807
+// `application/problem+json` bodies instead of plain text.
+func (e *Echo) UseProblemJSON(on bool) *Echo {
+	e.problemJSON = on
+	return e
+}
+
+// SetJSONContentLengthLimit makes `Context.JSON` and `Context.JSONBlob` set the
+// `Content-Length` header for the bodies up to limit bytes. `Context.JSON`
+// encodes the larger ones straight to the response without it, except in
+// debug mode. A limit <= 0 (the default) disables it.
+func (e *Echo) SetJSONContentLengthLimit(limit int) *Echo {
+	e.jsonLengthLimit = limit
+	return e
+}
+
+// JSONContentLengthLimit returns the limit set by `SetJSONContentLengthLimit`.
+func (e *Echo) JSONContentLengthLimit() int {
+	return e.jsonLengthLimit
+}
+
+// SetDefaultHeader sets a header, e.g. `Server` or `X-Powered-By`, on all the
+// responses before the handler runs. The handler can override or remove it.
+func (e *Echo) SetDefaultHeader(name, value string) *Echo {
+	e.defaultHeaders[http.CanonicalHeaderKey(name)] = value
+	return e
+}
+
+// RemoveDefaultHeader removes the header set by `SetDefaultHeader`.
+func (e *Echo) RemoveDefaultHeader(name string) *Echo {
+	delete(e.defaultHeaders, http.CanonicalHeaderKey(name))
+	return e
+}
+
+// SetBindHook sets a function called by `Context.BindAndValidate` after binding
+// and before validation, e.g. to trim strings or lowercase emails of the bound struct.
+func (e *Echo) SetBindHook(hook func(i interface{}, c Context) error) *Echo {
+	e.bindHook = hook
+	return e
+}
+
+// SetRouteConflictPolicy sets how `RebuildRouter` handles the routes registered
+// more than once with the same host, method and path. Default RouteConflictIgnore.
+func (e *Echo) SetRouteConflictPolicy(policy RouteConflictPolicy) *Echo {
+	e.routeConflict = policy
+	return e
+}
+
+// SetFormatExtension sets the default of `Context.WithFormatExtension`. With it
+// the router also removes a trailing format extension from the request path,
+// e.g. `/users/42.json` is routed as `/users/42` with the format forced to
+// `json`. Only the extensions of the registered format renderers are removed.
+func (e *Echo) SetFormatExtension(on bool) *Echo {
+	e.formatExtension = on
+	return e
+}
+
+// SetRenderFlushSize makes `Context.Render` stream the template output to the
+// response instead of buffering it, the response is flushed every time size
+// bytes have been written. A size <= 0 (the default) disables streaming.
+// Since the response is committed by the first write, a template error can
+// no longer change the status code and the client gets a truncated body.
+func (e *Echo) SetRenderFlushSize(size int) *Echo {
+	e.renderFlushSize = size
+	return e
+}
+
+// RenderFlushSize returns the size set by `SetRenderFlushSize`.
+func (e *Echo) RenderFlushSize() int {
+	return e.renderFlushSize
+}
+
+// SetRequireResponse sets whether a handler returning nil without writing a
+// response, which would answer an empty 200, is logged as a warning and
+// answered with ErrNoResponse (500).
+func (e *Echo) SetRequireResponse(on bool) *Echo {
+	e.requireResponse = on
+	return e
+}
+
 func (e *Echo) SetValidator(validator Validator) *Echo {
 	e.Validator = validator
 	return e
 }
 
+// SetDefaultCharset sets the charset appended to the content type of the
+// text, HTML, JSON, JSONP and XML responses. An empty charset omits it.
+func (e *Echo) SetDefaultCharset(charset string) *Echo {
+	e.defaultCharset = charset
+	return e
+}
+
+// DefaultCharset returns the charset of the text responses.
+func (e *Echo) DefaultCharset() string {
+	return e.defaultCharset
+}
+
+// ContentType returns the MIME type with the default charset.
+func (e *Echo) ContentType(mime string) string {
+	if len(e.defaultCharset) == 0 {
+		return mime
+	}
+	return mime + `; charset=` + e.defaultCharset
+}
+
+// MaxRequestBodySize returns `engine.Config.MaxRequestBodySize` of the engine
+// Echo runs on, or 0 if it is not limited there.
+func (e *Echo) MaxRequestBodySize() int64 {
+	if eng, ok := e.engine.(interface{ Config() *engine.Config }); ok {
+		if cfg := eng.Config(); cfg != nil {
+			return int64(cfg.MaxRequestBodySize)
+		}
+	}
+	return 0
+}
+
+// SetJSONDecodeOptions sets the options used by the binder to decode JSON request bodies.
+func (e *Echo) SetJSONDecodeOptions(options JSONDecodeOptions) *Echo {
+	e.jsonDecodeOptions = options
+	return e
+}
+
+// JSONDecodeOptions returns the options used by the binder to decode JSON request bodies.
+func (e *Echo) JSONDecodeOptions() JSONDecodeOptions {
+	return e.jsonDecodeOptions
+}
+
 func (e *Echo) SetFormSliceMaxIndex(max int) *Echo {
 	e.FormSliceMaxIndex = max
 	return e
 }
 
+// SetFormSliceMaxLength sets the maximum number of the repeated values, e.g.
+// `?id=1&id=2`, bound to a slice field. A max <= 0 disables the guard.
+func (e *Echo) SetFormSliceMaxLength(max int) *Echo {
+	e.FormSliceMaxLength = max
+	return e
+}
+
 func (e *Echo) SetAcceptFormats(acceptFormats map[string]string) *Echo {
 	e.acceptFormats = acceptFormats
 	return e
@@ -188,6 +365,31 @@ func (e *Echo) RemoveFormatRenderer(formats ...string) *Echo {
 	return e
 }
 
+// SetRequestDecoders sets the request body decoders keyed by `Content-Encoding`.
+// The map is copied, so later changes to it do not affect Echo.
+func (e *Echo) SetRequestDecoders(decoders map[string]func(io.Reader) (io.Reader, error)) *Echo {
+	e.requestDecoders = make(map[string]func(io.Reader) (io.Reader, error), len(decoders))
+	for encoding, decoder := range decoders {
+		e.AddRequestDecoder(encoding, decoder)
+	}
+	return e
+}
+
+// AddRequestDecoder registers a request body decoder for the `Content-Encoding`.
+// The encoding is matched case-insensitively.
+func (e *Echo) AddRequestDecoder(encoding string, decoder func(io.Reader) (io.Reader, error)) *Echo {
+	if e.requestDecoders == nil {
+		e.requestDecoders = make(map[string]func(io.Reader) (io.Reader, error))
+	}
+	e.requestDecoders[strings.ToLower(encoding)] = decoder
+	return e
+}
+
+// RequestDecoder returns the request body decoder registered for the `Content-Encoding`.
+func (e *Echo) RequestDecoder(encoding string) func(io.Reader) (io.Reader, error) {
+	return e.requestDecoders[strings.ToLower(encoding)]
+}
+
 // Router returns router.
 func (e *Echo) Router() *Router {
 	return e.router
@@ -210,6 +412,13 @@ func (e *Echo) Logger() logger.Logger {
 
 // DefaultHTTPErrorHandler invokes the default HTTP error handler.
 func (e *Echo) DefaultHTTPErrorHandler(err error, c Context) {
+	if ve, ok := err.(*ValidateErrors); ok {
+		if !c.Response().Committed() {
+			c.JSON(ve, http.StatusUnprocessableEntity)
+		}
+		e.logError(err, c, http.StatusUnprocessableEntity)
+		return
+	}
 	code := http.StatusInternalServerError
 	msg := http.StatusText(code)
 	if he, ok := err.(*HTTPError); ok {
@@ -222,7 +431,9 @@ func (e *Echo) DefaultHTTPErrorHandler(err error, c Context) {
 	if !c.Response().Committed() {
 		if c.Request().Method() == HEAD {
 			c.NoContent(code)
-		} else {
+		} else if e.problemJSON {
+			e.renderProblemJSON(c, code, msg)
+		} else if !e.renderErrorFormat(c, code, msg) && !e.renderErrorTemplate(c, code, msg) {
 			if code > 0 {
 				c.String(msg, code)
 			} else {
@@ -230,7 +441,117 @@ func (e *Echo) DefaultHTTPErrorHandler(err error, c Context) {
 			}
 		}
 	}
-	e.logger.Debug(err, `: `, c.Request().URL().String())
+	e.logError(err, c, code)
+}
+
+// logError logs the error handled by DefaultHTTPErrorHandler together with the
+// request method, path, status code and request ID. The fields are passed as
+// structured fields when the logger implements logger.FieldsLogger.
+func (e *Echo) logError(err error, c Context, code int) {
+	req := c.Request()
+	requestID := c.Response().Header().Get(HeaderXRequestID)
+	if len(requestID) == 0 {
+		requestID = req.Header().Get(HeaderXRequestID)
+	}
+	if l, ok := e.logger.(logger.FieldsLogger); ok {
+		fields := map[string]interface{}{
+			`method`: req.Method(),
+			`path`:   req.URL().Path(),
+			`status`: code,
+		}
+		if len(requestID) > 0 {
+			fields[`request_id`] = requestID
+		}
+		l.WithFields(fields).Debug(err)
+		return
+	}
+	if len(requestID) > 0 {
+		e.logger.Debugf(`%v: %s %s (status=%d, request_id=%s)`, err, req.Method(), req.URL().String(), code, requestID)
+		return
+	}
+	e.logger.Debugf(`%v: %s %s (status=%d)`, err, req.Method(), req.URL().String(), code)
+}
+
+// SetErrorTemplate maps an HTTP status code to the template rendered by
+// the default HTTP error handler, e.g. SetErrorTemplate(404, `errors/404.html`).
+// An empty name removes the mapping.
+func (e *Echo) SetErrorTemplate(code int, name string) *Echo {
+	if len(name) == 0 {
+		delete(e.errorTemplates, code)
+	} else {
+		e.errorTemplates[code] = name
+	}
+	return e
+}
+
+// SetAutoTemplateData sets whether the values stored by `Context.Set` are
+// merged into the map data of `Context.Render`. Explicit data takes precedence.
+func (e *Echo) SetAutoTemplateData(on bool) *Echo {
+	e.autoTemplateData = on
+	return e
+}
+
+// AutoTemplateData returns whether the context store is exposed to templates.
+func (e *Echo) AutoTemplateData() bool {
+	return e.autoTemplateData
+}
+
+// ErrorTemplate returns the template name registered for the HTTP status code.
+func (e *Echo) ErrorTemplate(code int) string {
+	return e.errorTemplates[code]
+}
+
+func (e *Echo) renderProblemJSON(c Context, code int, msg string) {
+	if code <= 0 {
+		code = http.StatusInternalServerError
+	}
+	b, err := json.Marshal(&ProblemDetails{
+		Type:     `about:blank`,
+		Title:    http.StatusText(code),
+		Status:   code,
+		Detail:   msg,
+		Instance: c.Request().URL().Path(),
+	})
+	if err != nil {
+		e.logger.Error(err)
+		return
+	}
+	c.Response().Header().Set(HeaderContentType, MIMEApplicationProblemJSON)
+	c.Blob(b, code)
+}
+
+// renderErrorFormat renders the error with the format renderer when the format
+// is forced by `Context.SetFormat`.
+func (e *Echo) renderErrorFormat(c Context, code int, msg string) bool {
+	if !c.Object().formatForced {
+		return false
+	}
+	format := c.Format()
+	render, ok := e.formatRenderers[format]
+	if !ok || render == nil {
+		return false
+	}
+	if code > 0 {
+		c.SetCode(code)
+	}
+	c.Data().SetError(errors.New(msg))
+	return render(c, msg) == nil
+}
+
+func (e *Echo) renderErrorTemplate(c Context, code int, msg string) bool {
+	name, ok := e.errorTemplates[code]
+	if !ok || c.Format() != `html` {
+		return false
+	}
+	b, err := c.Fetch(name, H{`Code`: code, `Message`: msg})
+	if err != nil {
+		if err != ErrRendererNotRegistered {
+			e.logger.Error(err)
+		}
+		return false
+	}
+	c.Response().Header().Set(HeaderContentType, e.ContentType(MIMETextHTML))
+	return c.Blob(b, code) == nil
 }
 
 // SetHTTPErrorHandler registers a custom Echo.HTTPErrorHandler.
@@ -304,11 +625,53 @@ func (e *Echo) Pre(middleware ...interface{}) {
 	e.premiddleware = append(middlewares, e.premiddleware...)
 }
 
+// PreUse adds handler to the beginning of the middleware chain, it runs before the
+// middleware added by `Use`. Unlike `Pre`, it runs after the router found the route.
+func (e *Echo) PreUse(middleware ...interface{}) {
+	var middlewares []interface{}
+	for _, m := range middleware {
+		e.ValidMiddleware(m)
+		middlewares = append(middlewares, m)
+		if e.MiddlewareDebug {
+			e.logger.Debugf(`Middleware[PreUse](%p): [] -> %s`, m, HandlerName(m))
+		}
+	}
+	e.middleware = append(middlewares, e.middleware...)
+}
+
 // Clear middleware
 func (e *Echo) Clear(middleware ...interface{}) {
 	e.middleware = Clear(e.middleware, middleware...)
 }
 
+// UseNamed adds the middleware to the chain under a name, which can be removed
+// by `ClearNamed`. A middleware already registered with the name is replaced in place.
+func (e *Echo) UseNamed(name string, middleware interface{}) {
+	m := &namedMiddleware{name: name, Middleware: e.ValidMiddleware(middleware)}
+	for i, v := range e.middleware {
+		if nm, ok := v.(*namedMiddleware); ok && nm.name == name {
+			e.middleware[i] = m
+			return
+		}
+	}
+	e.middleware = append(e.middleware, m)
+	if e.MiddlewareDebug {
+		e.logger.Debugf(`Middleware[Use](%s): [] -> %s `, name, HandlerName(middleware))
+	}
+}
+
+// ClearNamed removes the middleware registered by `UseNamed`.
+func (e *Echo) ClearNamed(names ...string) {
+	result := []interface{}{}
+	for _, v := range e.middleware {
+		if nm, ok := v.(*namedMiddleware); ok && nm.named(names) {
+			continue
+		}
+		result = append(result, v)
+	}
+	e.middleware = result
+}
+
 // ClearPre Clear premiddleware
 func (e *Echo) ClearPre(middleware ...interface{}) {
 	e.premiddleware = Clear(e.premiddleware, middleware...)
@@ -369,7 +732,7 @@ func (e *Echo) Any(path string, h interface{}, middleware ...interface{}) IRoute
 }
 
 func (e *Echo) Route(methods string, path string, h interface{}, middleware ...interface{}) IRouter {
-	return e.Match(splitHTTPMethod.Split(methods, -1), path, h, middleware...)
+	return e.Match(splitHTTPMethod.Split(strings.ToUpper(methods), -1), path, h, middleware...)
 }
 
 // Match adds a route > handler to the router for multiple HTTP methods provided.
@@ -397,6 +760,104 @@ func (e *Echo) File(path, file string) {
 	})
 }
 
+// SPAFallback registers a catch-all GET route serving the index file for the
+// paths no other route matches, so that a single-page application can handle
+// its own routing. Paths under one of the API prefixes are answered with a JSON
+// 404 instead, and so are the paths with a file extension (a missing asset) with
+// a plain 404. It panics if an API prefix is the root path.
+func (e *Echo) SPAFallback(indexFile string, apiPrefixes ...string) IRouter {
+	prefixes := make([]string, len(apiPrefixes))
+	for i, prefix := range apiPrefixes {
+		prefixes[i] = strings.TrimSuffix(prefix, `/`)
+		if len(prefixes[i]) == 0 {
+			panic(fmt.Sprintf("echo: invalid SPA fallback API prefix %q", prefix))
+		}
+	}
+	return e.Get(`/*`, func(c Context) error {
+		upath := c.Request().URL().Path()
+		if len(path.Ext(upath)) > 0 {
+			return ErrNotFound
+		}
+		for _, prefix := range prefixes {
+			if upath == prefix || strings.HasPrefix(upath, prefix+`/`) {
+				c.SetFormat(`json`)
+				return ErrNotFound
+			}
+		}
+		return c.File(indexFile)
+	})
+}
+
+// HealthCheck registers a GET endpoint which runs the checks concurrently
+// within `DefaultHealthCheckTimeout`. It responds 200 with `{"status":"ok"}`
+// when all of them pass, otherwise 503 with the failures keyed by check name,
+// suffixed by `#index` when several checks have the same name, see
+// `NamedHealthCheck` to name the closures. The checks share the request
+// context, whose `Done()` is closed at the timeout, and must return then: a
+// check returning after the timeout fails with `context.DeadlineExceeded`.
+func (e *Echo) HealthCheck(path string, checks ...func(c Context) error) IRouter {
+	names := make([]string, len(checks))
+	seen := make(map[string]bool, len(checks))
+	for index, check := range checks {
+		name := HandlerName(check)
+		if seen[name] {
+			name += `#` + strconv.Itoa(index)
+		}
+		seen[name] = true
+		names[index] = name
+	}
+	return e.healthCheck(path, names, checks)
+}
+
+// NamedHealthCheck is like `HealthCheck`, the failures are keyed by the names
+// of the checks.
+func (e *Echo) NamedHealthCheck(path string, checks map[string]func(c Context) error) IRouter {
+	names := make([]string, 0, len(checks))
+	for name := range checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	list := make([]func(c Context) error, len(names))
+	for index, name := range names {
+		list[index] = checks[name]
+	}
+	return e.healthCheck(path, names, list)
+}
+
+func (e *Echo) healthCheck(path string, names []string, checks []func(c Context) error) IRouter {
+	return e.Get(path, func(c Context) error {
+		parent := c.StdContext()
+		ctx, cancel := context.WithTimeout(parent, DefaultHealthCheckTimeout)
+		defer cancel()
+		c.SetStdContext(ctx)
+		errs := make([]error, len(checks))
+		wg := sync.WaitGroup{}
+		for index, check := range checks {
+			wg.Add(1)
+			go func(index int, check func(c Context) error) {
+				defer wg.Done()
+				err := check(c)
+				if err == nil {
+					err = ctx.Err()
+				}
+				errs[index] = err
+			}(index, check)
+		}
+		wg.Wait()
+		c.SetStdContext(parent)
+		failures := H{}
+		for index, err := range errs {
+			if err != nil {
+				failures[names[index]] = err.Error()
+			}
+		}
+		if len(failures) > 0 {
+			return c.JSON(H{`status`: `error`, `failures`: failures}, http.StatusServiceUnavailable)
+		}
+		return c.JSON(H{`status`: `ok`})
+	})
+}
+
 func (e *Echo) ValidHandler(v interface{}) (h Handler) {
 	if e.handlerWrapper != nil {
 		for _, wrapper := range e.handlerWrapper {
@@ -458,7 +919,7 @@ func (e *Echo) SetPrefix(prefix string) *Echo {
 func (e *Echo) add(host, method, prefix string, path string, h interface{}, middleware ...interface{}) *Route {
 	r := &Route{
 		Host:       host,
-		Method:     method,
+		Method:     strings.ToUpper(method),
 		Path:       e.prefix + path,
 		Prefix:     prefix,
 		handler:    h,
@@ -496,7 +957,13 @@ func (e *Echo) RebuildRouter(args ...[]*Route) *Echo {
 	if len(args) > 0 {
 		routes = args[0]
 	}
+	if e.routeConflict != RouteConflictIgnore {
+		routes = resolveRouteConflicts(e, routes, e.routeConflict)
+	}
 	e.router = NewRouter(e)
+	for _, h := range e.hosts {
+		h.Router = NewRouter(e)
+	}
 	for i, r := range routes {
 		router, _, _, _ := e.findRouter(r.Host)
 		r.apply(e)
@@ -515,11 +982,31 @@ func (e *Echo) RebuildRouter(args ...[]*Route) *Echo {
 	return e
 }
 
-// AppendRouter append router
+// AppendRouter append router, the route conflict policy applies to the routes
+// added against the registered ones and each other.
 func (e *Echo) AppendRouter(routes []*Route) *Echo {
-	for i, r := range routes {
+	if e.routeConflict != RouteConflictIgnore {
+		keys := make(map[string]struct{}, len(e.router.routes)+len(routes))
+		for _, r := range e.router.routes {
+			keys[routeConflictKey(r)] = struct{}{}
+		}
+		for _, r := range routes {
+			key := routeConflictKey(r)
+			if _, ok := keys[key]; ok {
+				if e.routeConflict == RouteConflictLastWins {
+					// the route replaced is removed, the router is rebuilt
+					all := make([]*Route, 0, len(e.router.routes)+len(routes))
+					all = append(all, e.router.routes...)
+					return e.RebuildRouter(append(all, routes...))
+				}
+				reportRouteConflict(e, r, e.routeConflict)
+			}
+			keys[key] = struct{}{}
+		}
+	}
+	for _, r := range routes {
 		router, _, _, _ := e.findRouter(r.Host)
-		i = len(e.router.routes)
+		i := len(e.router.routes)
 		r.apply(e)
 		router.Add(r, i)
 		if _, ok := e.router.nroute[r.Name]; !ok {
@@ -549,6 +1036,9 @@ func (e *Echo) Host(name string, m ...interface{}) *Group {
 			Router: NewRouter(e),
 		}
 		e.hosts[name] = h
+		if len(e.groupDefaults) > 0 {
+			h.group.Use(e.groupDefaults...)
+		}
 	}
 	if len(m) > 0 {
 		h.group.Use(m...)
@@ -568,12 +1058,26 @@ func (e *Echo) TypeHost(alias string, args ...interface{}) (r TypeHost) {
 	return
 }
 
+// SetGroupDefaults sets the middleware prepended to the chain of every group
+// created afterwards by `Echo.Group` or `Echo.Host`.
+func (e *Echo) SetGroupDefaults(middleware ...interface{}) *Echo {
+	e.groupDefaults = middleware
+	return e
+}
+
 // Group creates a new sub-router with prefix.
 func (e *Echo) Group(prefix string, m ...interface{}) *Group {
+	return e.group(prefix, e.groupDefaults, m...)
+}
+
+func (e *Echo) group(prefix string, defaults []interface{}, m ...interface{}) *Group {
 	g, y := e.groups[prefix]
 	if !y {
 		g = &Group{prefix: prefix, echo: e}
 		e.groups[prefix] = g
+		if len(defaults) > 0 {
+			g.Use(defaults...)
+		}
 	}
 	if len(m) > 0 {
 		g.Use(m...)
@@ -613,6 +1117,26 @@ func (e *Echo) Routes() []*Route {
 	return e.router.routes
 }
 
+// MiddlewareChain returns the names of the middleware run for the route of the
+// default host matching method and path, in execution order: `Pre`, `Use`,
+// group and route middleware. It returns nil when no route matches.
+func (e *Echo) MiddlewareChain(method, path string) []string {
+	ctx := NewContext(nil, nil, e).Object()
+	ctx.rid = -1
+	e.router.Find(method, path, ctx)
+	if ctx.rid < 0 || ctx.rid >= len(e.router.routes) {
+		return nil
+	}
+	r := e.router.routes[ctx.rid]
+	names := make([]string, 0, len(e.premiddleware)+len(e.middleware)+len(r.middleware))
+	for _, chain := range [][]interface{}{e.premiddleware, e.middleware, r.middleware} {
+		for _, m := range chain {
+			names = append(names, middlewareName(m))
+		}
+	}
+	return names
+}
+
 // NamedRoutes returns the registered handler name.
 func (e *Echo) NamedRoutes() map[string][]int {
 	return e.router.nroute
@@ -626,11 +1150,12 @@ func (e *Echo) applyMiddleware(h Handler, middleware ...interface{}) Handler {
 }
 
 func (e *Echo) buildHandler(c Context) Handler {
-	if r, names, values, exist := e.findRouter(c.Host()); exist {
+	if h, names, values := e.findHost(c.Host()); h != nil {
 		if len(names) > 0 {
 			c.setHostParamValues(names, values)
 		}
-		return e.applyMiddleware(r.Handle(c), e.middleware...)
+		c.Object().host = h
+		return e.applyMiddleware(h.Router.Handle(c), e.middleware...)
 	}
 	return e.applyMiddleware(e.router.Handle(c), e.middleware...)
 }
@@ -638,6 +1163,27 @@ func (e *Echo) buildHandler(c Context) Handler {
 func (e *Echo) ServeHTTP(req engine.Request, res engine.Response) {
 	c := e.pool.Get().(Context)
 	c.Reset(req, res)
+	c.SetStdContext(e.shutdownCtx)
+	for name, value := range e.defaultHeaders {
+		res.Header().Set(name, value)
+	}
+	defer func() {
+		// Reset clears the context on the next use, so it is pooled even after a panic.
+		if r := recover(); r != nil {
+			if r == http.ErrAbortHandler {
+				panic(r)
+			}
+			panicErr := NewPanicError(r, nil, e.debug).Parse()
+			e.logger.Error(panicErr)
+			c.Error(panicErr)
+		}
+		c.Object().postResponse()
+		if engine.IsHijacked(res) {
+			// The hijacked connection may still use the context.
+			return
+		}
+		e.pool.Put(c)
+	}()
 
 	var h Handler
 	if len(e.premiddleware) > 0 {
@@ -649,9 +1195,10 @@ func (e *Echo) ServeHTTP(req engine.Request, res engine.Response) {
 	}
 	if err := h.Handle(c); err != nil {
 		c.Error(err)
+	} else if e.requireResponse && !res.Committed() && !engine.IsHijacked(res) {
+		e.logger.Warnf(`%s %s: %v`, req.Method(), req.URL().Path(), ErrNoResponse)
+		c.Error(ErrNoResponse)
 	}
-
-	e.pool.Put(c)
 }
 
 // Run starts the HTTP engine.
@@ -663,6 +1210,34 @@ func (e *Echo) Run(eng engine.Engine, handler ...engine.Handler) error {
 	return err
 }
 
+// RunURL starts the engine registered as `standard` on the listener described
+// by the URL, e.g. `http://:8080` or `unix:///tmp/app.sock`. The engine must be
+// registered by importing `github.com/webx-top/echo/engine/standard`.
+func (e *Echo) RunURL(rawURL string, handler ...engine.Handler) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	var address string
+	switch u.Scheme {
+	case `http`:
+		address = `tcp://` + u.Host
+	case `unix`:
+		address = `unix://` + u.Path
+	default:
+		return fmt.Errorf(`unsupported URL scheme: %q`, u.Scheme)
+	}
+	newEngine := engine.Get(`standard`)
+	if newEngine == nil {
+		return errors.New(`engine "standard" is not registered`)
+	}
+	ln, err := engine.NewListener(address, false)
+	if err != nil {
+		return err
+	}
+	return e.Run(newEngine(&engine.Config{Address: ln.Addr().String(), Listener: ln}), handler...)
+}
+
 func (e *Echo) Commit() *Echo {
 	e.buildRouter()
 	return e
@@ -675,6 +1250,7 @@ func (e *Echo) start(handler ...engine.Handler) error {
 		e.engine.SetHandler(e)
 	}
 	e.engine.SetLogger(e.logger)
+	e.resetShutdown()
 	if e.Debug() {
 		e.logger.Debug("running in debug mode")
 	}
@@ -698,7 +1274,12 @@ func (e *Echo) Stop() error {
 	return e.engine.Stop()
 }
 
+// Shutdown gracefully shuts down the HTTP server. The contexts of the
+// in-flight requests are cancelled at once, so that their handlers observe
+// `Context#Done()` and bail out early, and the server waits for them to
+// finish until ctx is done.
 func (e *Echo) Shutdown(ctx context.Context) error {
+	e.shutdownCancel()
 	if e.engine == nil {
 		return nil
 	}
@@ -706,11 +1287,19 @@ func (e *Echo) Shutdown(ctx context.Context) error {
 }
 
 func (e *Echo) findRouter(host string) (*Router, []string, []string, bool) {
+	if h, names, values := e.findHost(host); h != nil {
+		return h.Router, names, values, true
+	}
+	return e.router, nil, nil, false
+}
+
+// findHost returns the Host matching the host name, or nil for the default host.
+func (e *Echo) findHost(host string) (*Host, []string, []string) {
 	if len(e.hosts) == 0 {
-		return e.router, nil, nil, false
+		return nil, nil, nil
 	}
 	if r, ok := e.hosts[host]; ok {
-		return r.Router, nil, nil, true
+		return r, nil, nil
 	}
 	l := len(host)
 	for h, r := range e.hosts {
@@ -718,7 +1307,7 @@ func (e *Echo) findRouter(host string) (*Router, []string, []string, bool) {
 			values, hasExpr := r.group.host.Match(host)
 			if hasExpr {
 				if len(values) > 0 {
-					return r.Router, r.group.host.names, values, true
+					return r, r.group.host.names, values
 				}
 				continue
 			}
@@ -727,13 +1316,13 @@ func (e *Echo) findRouter(host string) (*Router, []string, []string, bool) {
 			continue
 		}
 		if h[0] == '.' && strings.HasSuffix(host, h) { //.host(xxx.host)
-			return r.Router, nil, nil, true
+			return r, nil, nil
 		}
 		if h[len(h)-1] == '.' && strings.HasPrefix(host, h) { //host.(host.xxx)
-			return r.Router, nil, nil, true
+			return r, nil, nil
 		}
 	}
-	return e.router, nil, nil, false
+	return nil, nil, nil
 }
 
 func (e *Echo) NewContext(req engine.Request, resp engine.Response) Context {
@@ -2,10 +2,12 @@ package echo
 
 type (
 	Host struct {
-		head   Handler
-		group  *Group
-		groups map[string]*Group
-		Router *Router
+		head             Handler
+		group            *Group
+		groups           map[string]*Group
+		notFoundHandler  Handler
+		httpErrorHandler HTTPErrorHandler
+		Router           *Router
 	}
 	TypeHost struct {
 		prefix string
@@ -21,6 +23,20 @@ func (t TypeHost) URI(handler interface{}, params ...interface{}) string {
 	return t.prefix + t.echo.URI(handler, params...)
 }
 
+// SetNotFoundHandler sets the handler answering the `ErrNotFound` errors of
+// the requests matched to the host.
+func (h *Host) SetNotFoundHandler(handler Handler) *Host {
+	h.notFoundHandler = handler
+	return h
+}
+
+// SetHTTPErrorHandler sets the error handler of the requests matched to the
+// host, it overrides `Echo.SetHTTPErrorHandler` for them.
+func (h *Host) SetHTTPErrorHandler(handler HTTPErrorHandler) *Host {
+	h.httpErrorHandler = handler
+	return h
+}
+
 func (h *Host) Host(args ...interface{}) (r TypeHost) {
 	if h.group == nil || h.group.host == nil {
 		return
@@ -1,14 +1,24 @@
 package echo_test
 
 import (
+	"bufio"
 	"bytes"
+	"compress/gzip"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"net"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/admpub/log"
 	"github.com/stretchr/testify/assert"
@@ -16,6 +26,9 @@ import (
 	"github.com/webx-top/echo"
 	. "github.com/webx-top/echo"
 	"github.com/webx-top/echo/code"
+	"github.com/webx-top/echo/engine"
+	_ "github.com/webx-top/echo/engine/standard"
+	"github.com/webx-top/echo/logger"
 	mw "github.com/webx-top/echo/middleware"
 	test "github.com/webx-top/echo/testing"
 	"github.com/webx-top/validation"
@@ -116,6 +129,68 @@ func TestEchoRoutePath(t *testing.T) {
 	assert.Equal(t, `/`, r)
 }
 
+func TestGroupMultiMethodRoutes(t *testing.T) {
+	e := New()
+	var called int
+	g := e.Group("/api", func(next HandlerFunc) HandlerFunc {
+		return func(c Context) error {
+			called++
+			return next.Handle(c)
+		}
+	})
+	handler := func(c Context) error {
+		return c.String(c.Request().Method())
+	}
+	g.Any("/any", handler)
+	g.Route("get,post", "/route", handler)
+	g.Match([]string{PUT, DELETE}, "/match", handler)
+	e.RebuildRouter()
+
+	for _, method := range []string{CONNECT, DELETE, GET, OPTIONS, PATCH, POST, PUT, TRACE} {
+		c, b := request(method, "/api/any", e)
+		assert.Equal(t, http.StatusOK, c, method)
+		assert.Equal(t, method, b)
+	}
+	assert.Equal(t, 8, called)
+
+	c, b := request(POST, "/api/route", e)
+	assert.Equal(t, http.StatusOK, c)
+	assert.Equal(t, POST, b)
+	c, _ = request(PUT, "/api/route", e)
+	assert.Equal(t, http.StatusMethodNotAllowed, c)
+
+	c, b = request(DELETE, "/api/match", e)
+	assert.Equal(t, http.StatusOK, c)
+	assert.Equal(t, DELETE, b)
+	c, _ = request(GET, "/any", e)
+	assert.Equal(t, http.StatusNotFound, c)
+}
+
+func TestGroupPreUse(t *testing.T) {
+	e := New()
+	buf := new(bytes.Buffer)
+	write := func(s string) func(next HandlerFunc) HandlerFunc {
+		return func(next HandlerFunc) HandlerFunc {
+			return func(c Context) error {
+				buf.WriteString(s)
+				return next.Handle(c)
+			}
+		}
+	}
+	e.Use(write("e1"))
+	e.PreUse(write("e0"))
+	g := e.Group("/g", write("g2"))
+	g.Use(write("g3"))
+	g.PreUse(write("g0"), write("g1"))
+	g.Get("/a", func(c Context) error {
+		return c.String(buf.String())
+	})
+	e.RebuildRouter()
+
+	_, b := request(GET, "/g/a", e)
+	assert.Equal(t, "e0e1g0g1g2g3", b)
+}
+
 func TestGroupMiddleware(t *testing.T) {
 	e := New()
 	buf := new(bytes.Buffer)
@@ -266,6 +341,274 @@ func TestEchoHandler(t *testing.T) {
 	assert.Equal(t, "10001.admpub.com/host2", e.TypeHost(`user`, echo.H{`uid`: 10001, `name`: `admpub`}).URI(`host2`))
 }
 
+type testRPCRequest struct {
+	Name string `json:"name"`
+}
+
+type testRPCResponse struct {
+	Greeting string `json:"greeting"`
+}
+
+func TestEchoRPCHandlerWrapper(t *testing.T) {
+	e := New()
+	e.AddHandlerWrapper(WrapRPCHandler)
+	e.Post("/greet", func(c Context, in *testRPCRequest) (*testRPCResponse, error) {
+		if len(in.Name) == 0 {
+			return nil, NewHTTPError(http.StatusBadRequest, `name is required`)
+		}
+		return &testRPCResponse{Greeting: `hello ` + in.Name}, nil
+	})
+	e.RebuildRouter()
+
+	rec := test.Request(POST, "/greet", e, func(req *http.Request) {
+		req.Header.Set(HeaderContentType, MIMEApplicationJSON)
+		req.Body = ioutil.NopCloser(bytes.NewBufferString(`{"name":"webx"}`))
+	})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get(HeaderContentType), MIMEApplicationJSON)
+	assert.Equal(t, `{"greeting":"hello webx"}`, rec.Body.String())
+
+	rec = test.Request(POST, "/greet", e, func(req *http.Request) {
+		req.Header.Set(HeaderContentType, MIMEApplicationJSON)
+		req.Body = ioutil.NopCloser(bytes.NewBufferString(`{}`))
+	})
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestEchoStaticPrefix(t *testing.T) {
+	root, err := ioutil.TempDir(``, `echo-static`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	ioutil.WriteFile(filepath.Join(root, `index.html`), []byte(`index`), 0644)
+	ioutil.WriteFile(filepath.Join(root, `file.css`), []byte(`body{}`), 0644)
+
+	for _, prefix := range []string{`/static`, `/static/`} {
+		e := New()
+		e.Static(prefix, root)
+		e.RebuildRouter()
+
+		rec := test.Request(GET, "/static", e)
+		assert.Equal(t, http.StatusMovedPermanently, rec.Code, prefix)
+		assert.Equal(t, "/static/", rec.Header().Get(HeaderLocation), prefix)
+
+		c, b := request(GET, "/static/", e)
+		assert.Equal(t, http.StatusOK, c, prefix)
+		assert.Equal(t, "index", b, prefix)
+
+		c, b = request(GET, "/static/file.css", e)
+		assert.Equal(t, http.StatusOK, c, prefix)
+		assert.Equal(t, "body{}", b, prefix)
+	}
+}
+
+func TestEchoStaticPrecompressed(t *testing.T) {
+	root, err := ioutil.TempDir(``, `echo-static`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	ioutil.WriteFile(filepath.Join(root, `app.js`), []byte(`plain`), 0644)
+	ioutil.WriteFile(filepath.Join(root, `app.js.br`), []byte(`brotli`), 0644)
+	ioutil.WriteFile(filepath.Join(root, `app.js.gz`), []byte(`gzip`), 0644)
+	ioutil.WriteFile(filepath.Join(root, `other.js`), []byte(`other`), 0644)
+
+	e := New()
+	e.Static(`/static`, root)
+	e.RebuildRouter()
+
+	acceptEncoding := func(value string) func(*http.Request) {
+		return func(req *http.Request) {
+			req.Header.Set(HeaderAcceptEncoding, value)
+		}
+	}
+	rec := test.Request(GET, "/static/app.js", e, acceptEncoding(`gzip, deflate, br`))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `brotli`, rec.Body.String())
+	assert.Equal(t, `br`, rec.Header().Get(HeaderContentEncoding))
+	assert.Contains(t, rec.Header().Get(HeaderContentType), `javascript`)
+	assert.Equal(t, HeaderAcceptEncoding, rec.Header().Get(HeaderVary))
+
+	rec = test.Request(GET, "/static/app.js", e, acceptEncoding(`gzip, br;q=0`))
+	assert.Equal(t, `gzip`, rec.Body.String())
+	assert.Equal(t, `gzip`, rec.Header().Get(HeaderContentEncoding))
+
+	rec = test.Request(GET, "/static/app.js", e)
+	assert.Equal(t, `plain`, rec.Body.String())
+	assert.Empty(t, rec.Header().Get(HeaderContentEncoding))
+	assert.Equal(t, HeaderAcceptEncoding, rec.Header().Get(HeaderVary))
+
+	rec = test.Request(GET, "/static/other.js", e, acceptEncoding(`br`))
+	assert.Equal(t, `other`, rec.Body.String())
+	assert.Empty(t, rec.Header().Get(HeaderContentEncoding))
+	assert.Empty(t, rec.Header().Get(HeaderVary))
+
+	// the response is already gzipped by the middleware
+	e = New()
+	e.Use(mw.Gzip())
+	e.Static(`/static`, root)
+	e.RebuildRouter()
+	rec = test.Request(GET, "/static/app.js", e, acceptEncoding(`gzip, br`))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `gzip`, rec.Header().Get(HeaderContentEncoding))
+	r, err := gzip.NewReader(rec.Body)
+	assert.NoError(t, err)
+	b, err := ioutil.ReadAll(r)
+	assert.NoError(t, err)
+	assert.Equal(t, `plain`, string(b))
+}
+
+func TestEchoErrorHandlerReadsStore(t *testing.T) {
+	e := New()
+	e.Use(func(h Handler) HandlerFunc {
+		return func(c Context) error {
+			c.Set(`requestID`, `req-1`)
+			return h.Handle(c)
+		}
+	})
+	e.SetHTTPErrorHandler(func(err error, c Context) {
+		c.String(fmt.Sprintf(`%v %v: %v`, c.Get(`requestID`), c.Get(`user`), err), http.StatusInternalServerError)
+	})
+	e.Get("/fail", func(c Context) error {
+		c.Set(`user`, `admin`)
+		return errors.New(`failed`)
+	})
+	e.RebuildRouter()
+
+	c, b := request(GET, "/fail", e)
+	assert.Equal(t, http.StatusInternalServerError, c)
+	assert.Equal(t, "req-1 admin: failed", b)
+}
+
+func TestEchoRoutePriority(t *testing.T) {
+	e := New()
+	e.Get("/users/new", func(c Context) error {
+		return c.String(`new`)
+	})
+	e.Get("/users/:id", func(c Context) error {
+		return c.String(`user ` + c.Param(`id`))
+	})
+	e.RebuildRouter()
+
+	_, b := request(GET, "/users/new", e)
+	assert.Equal(t, `new`, b)
+	_, b = request(GET, "/users/1", e)
+	assert.Equal(t, `user 1`, b)
+
+	e = New()
+	e.Get("/users/new", func(c Context) error {
+		return c.String(`new`)
+	})
+	e.Get("/users/:id", func(c Context) error {
+		return c.String(`user ` + c.Param(`id`))
+	}).SetPriority(1)
+	e.Get("/users/*", func(c Context) error {
+		return c.String(`fallback`)
+	}).SetPriority(-1)
+	e.Get("/posts/*", func(c Context) error {
+		return c.String(`posts`)
+	}).SetPriority(-1)
+	e.RebuildRouter()
+
+	_, b = request(GET, "/users/new", e)
+	assert.Equal(t, `user new`, b)
+	_, b = request(GET, "/users/1", e)
+	assert.Equal(t, `user 1`, b)
+	_, b = request(GET, "/users/1/posts", e)
+	assert.Equal(t, `fallback`, b)
+	_, b = request(GET, "/posts/1", e)
+	assert.Equal(t, `posts`, b)
+	c, _ := request(POST, "/users/new", e)
+	assert.Equal(t, http.StatusMethodNotAllowed, c)
+	c, _ = request(POST, "/users/1", e)
+	assert.Equal(t, http.StatusMethodNotAllowed, c)
+	c, _ = request(GET, "/missing", e)
+	assert.Equal(t, http.StatusNotFound, c)
+	assert.Contains(t, e.Router().Tree(), `(priority 1)`)
+	assert.Contains(t, e.Router().Tree(), `(priority -1)`)
+}
+
+func TestEchoHostErrorHandlers(t *testing.T) {
+	e := New()
+	for _, name := range []string{`a`, `b`} {
+		name := name
+		host := name + `.example.com`
+		e.Host(host).Get("/fail", func(c Context) error {
+			return errors.New(`failed`)
+		})
+		e.Hosts()[host].SetHTTPErrorHandler(func(err error, c Context) {
+			c.String(`host `+name+`: `+err.Error(), http.StatusInternalServerError)
+		}).SetNotFoundHandler(HandlerFunc(func(c Context) error {
+			return c.String(`host `+name+`: page not found`, http.StatusNotFound)
+		}))
+	}
+	e.Host(`c.example.com`).Get("/fail", func(c Context) error {
+		return errors.New(`failed`)
+	})
+	e.RebuildRouter()
+
+	requestHost := func(host string, path string) (int, string) {
+		return request(GET, path, e, func(req *http.Request) {
+			req.Host = host
+		})
+	}
+	c, b := requestHost(`a.example.com`, `/fail`)
+	assert.Equal(t, http.StatusInternalServerError, c)
+	assert.Equal(t, `host a: failed`, b)
+	c, b = requestHost(`b.example.com`, `/fail`)
+	assert.Equal(t, http.StatusInternalServerError, c)
+	assert.Equal(t, `host b: failed`, b)
+
+	c, b = requestHost(`a.example.com`, `/missing`)
+	assert.Equal(t, http.StatusNotFound, c)
+	assert.Equal(t, `host a: page not found`, b)
+	c, b = requestHost(`b.example.com`, `/missing`)
+	assert.Equal(t, http.StatusNotFound, c)
+	assert.Equal(t, `host b: page not found`, b)
+
+	// hosts without their own handlers use the Echo ones
+	c, b = requestHost(`c.example.com`, `/fail`)
+	assert.Equal(t, http.StatusInternalServerError, c)
+	assert.NotContains(t, b, `host`)
+}
+
+func TestEchoHostMount(t *testing.T) {
+	e := New()
+	admin := e.Group("/admin", func(h Handler) HandlerFunc {
+		return func(c Context) error {
+			c.Response().Header().Set(`X-Admin`, `1`)
+			return h.Handle(c)
+		}
+	})
+	admin.Get("/users", func(c Context) error {
+		return c.String(`users@` + c.Host())
+	}).SetName(`admin.users`)
+	e.Host("admin.example.com").Mount("", admin)
+	admin.Get("/settings", func(c Context) error {
+		return c.String(`settings`)
+	})
+	e.RebuildRouter()
+
+	rec := test.Request(GET, "/users", e, func(req *http.Request) {
+		req.Host = "admin.example.com"
+	})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "users@admin.example.com", rec.Body.String())
+	assert.Equal(t, "1", rec.Header().Get(`X-Admin`))
+
+	c, b := request(GET, "/settings", e, func(req *http.Request) {
+		req.Host = "admin.example.com"
+	})
+	assert.Equal(t, http.StatusOK, c)
+	assert.Equal(t, "settings", b)
+
+	c, _ = request(GET, "/admin/users", e)
+	assert.Equal(t, http.StatusNotFound, c)
+	c, _ = request(GET, "/users", e)
+	assert.Equal(t, http.StatusNotFound, c)
+}
+
 func TestEchoRouter(t *testing.T) {
 	e := New()
 
@@ -305,6 +648,45 @@ func (m *MetaRequest) Filters(c echo.Context) []FormDataFilter {
 	return nil
 }
 
+func TestEchoOptionalParam(t *testing.T) {
+	e := New()
+	e.Get("/posts/:year/:month?", func(c Context) error {
+		return c.String(c.Param(`year`) + `|` + c.Param(`month`) + `|` + c.Path())
+	}).SetName(`archive`)
+	e.RebuildRouter()
+
+	c, b := request(GET, "/posts/2024", e)
+	assert.Equal(t, http.StatusOK, c)
+	assert.Equal(t, `2024||/posts/:year/:month?`, b)
+
+	c, b = request(GET, "/posts/2024/03", e)
+	assert.Equal(t, http.StatusOK, c)
+	assert.Equal(t, `2024|03|/posts/:year/:month?`, b)
+
+	assert.Equal(t, `/posts/2024`, e.URI(`archive`, 2024))
+	assert.Equal(t, `/posts/2024/03`, e.URI(`archive`, 2024, `03`))
+	assert.Equal(t, `/posts/2024`, e.URI(`archive`, map[string]string{`year`: `2024`}))
+	assert.Equal(t, `/posts/2024/03`, e.URI(`archive`, url.Values{`year`: {`2024`}, `month`: {`03`}}))
+}
+
+func TestRouterTree(t *testing.T) {
+	e := New()
+	h := func(c Context) error {
+		return c.String(c.Path())
+	}
+	e.Get("/users/:id", h).SetName(`user`)
+	e.Post("/users", h).SetName(`createUser`)
+	e.Get("/users", h).SetName(`users`)
+	e.Get("/", h).SetName(`index`)
+	e.RebuildRouter()
+
+	expected := "/ -> / [GET index]\n" +
+		"  users -> /users [GET users, POST createUser]\n" +
+		"    /\n" +
+		"      : -> /users/:id [GET user]\n"
+	assert.Equal(t, expected, e.Router().Tree())
+}
+
 func TestEchoMeta(t *testing.T) {
 	e := New()
 	e.SetDebug(true)
@@ -366,6 +748,109 @@ func TestEchoMeta(t *testing.T) {
 	assert.Equal(t, `Name: Can not be empty`, b)
 }
 
+func TestEchoRouteWithMeta(t *testing.T) {
+	e := New()
+	e.Use(func(h Handler) HandlerFunc {
+		return func(c Context) error {
+			meta := c.Route().Meta
+			c.Response().Header().Set(`X-Permission`, meta.String(`permission`))
+			if meta.Bool(`private`) && meta.Int(`level`) > 1 {
+				return c.NoContent(http.StatusForbidden)
+			}
+			return h.Handle(c)
+		}
+	})
+	e.Get("/public", func(c Context) error {
+		return c.String(`public`)
+	}).WithMeta(H{`permission`: `read`, `level`: 1})
+	e.Get("/admin", e.MetaHandler(
+		H{`permission`: `read`, `private`: true},
+		func(c Context) error {
+			return c.String(`admin`)
+		},
+	)).WithMeta(H{`permission`: `manage`, `level`: `2`})
+	e.RebuildRouter()
+
+	rec := test.Request(GET, "/public", e)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `read`, rec.Header().Get(`X-Permission`))
+
+	rec = test.Request(GET, "/admin", e)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Equal(t, `manage`, rec.Header().Get(`X-Permission`))
+}
+
+func healthCheckOK(c Context) error {
+	return nil
+}
+
+func healthCheckDatabase(c Context) error {
+	return errors.New(`connection refused`)
+}
+
+func TestEchoHealthCheck(t *testing.T) {
+	e := New()
+	e.HealthCheck("/healthz", healthCheckOK, healthCheckOK)
+	e.HealthCheck("/readyz", healthCheckOK, healthCheckDatabase)
+	e.RebuildRouter()
+
+	c, b := request(GET, "/healthz", e)
+	assert.Equal(t, http.StatusOK, c)
+	assert.JSONEq(t, `{"status":"ok"}`, b)
+
+	c, b = request(GET, "/readyz", e)
+	assert.Equal(t, http.StatusServiceUnavailable, c)
+	resp := H{}
+	assert.NoError(t, json.Unmarshal([]byte(b), &resp))
+	assert.Equal(t, `error`, resp.String(`status`))
+	failures := resp.Store(`failures`)
+	assert.Len(t, failures, 1)
+	assert.Equal(t, `connection refused`, failures.String(HandlerName(healthCheckDatabase)))
+
+	e.HealthCheck("/livez", healthCheckDatabase, healthCheckOK, healthCheckDatabase)
+	e.RebuildRouter()
+	c, b = request(GET, "/livez", e)
+	assert.Equal(t, http.StatusServiceUnavailable, c)
+	resp = H{}
+	assert.NoError(t, json.Unmarshal([]byte(b), &resp))
+	failures = resp.Store(`failures`)
+	assert.Len(t, failures, 2)
+	assert.Equal(t, `connection refused`, failures.String(HandlerName(healthCheckDatabase)))
+	assert.Equal(t, `connection refused`, failures.String(HandlerName(healthCheckDatabase)+`#2`))
+
+	timeout := DefaultHealthCheckTimeout
+	DefaultHealthCheckTimeout = 10 * time.Millisecond
+	defer func() {
+		DefaultHealthCheckTimeout = timeout
+	}()
+	cancelled := make(chan struct{})
+	e.HealthCheck("/slowz", func(c Context) error {
+		<-c.Done()
+		close(cancelled)
+		return nil
+	})
+	e.RebuildRouter()
+	c, b = request(GET, "/slowz", e)
+	assert.Equal(t, http.StatusServiceUnavailable, c)
+	assert.Contains(t, b, context.DeadlineExceeded.Error())
+	<-cancelled
+}
+
+func TestEchoNamedHealthCheck(t *testing.T) {
+	e := New()
+	e.NamedHealthCheck("/readyz", map[string]func(c Context) error{
+		`cache`: healthCheckOK,
+		`database`: func(c Context) error {
+			return errors.New(`connection refused`)
+		},
+	})
+	e.RebuildRouter()
+
+	c, b := request(GET, "/readyz", e)
+	assert.Equal(t, http.StatusServiceUnavailable, c)
+	assert.JSONEq(t, `{"status":"error","failures":{"database":"connection refused"}}`, b)
+}
+
 func TestEchoData(t *testing.T) {
 	data := NewData(nil)
 	data.SetCode(0)
@@ -375,3 +860,771 @@ func TestEchoData(t *testing.T) {
 	assert.Equal(t, `Failure`, fmt.Sprintf(`%s`, data.Code))
 	assert.Equal(t, `Failure`, data.State)
 }
+
+type testRenderer struct{}
+
+func (testRenderer) Render(w io.Writer, name string, data interface{}, c Context) error {
+	_, err := fmt.Fprintf(w, `%s:%v`, name, data.(H).Get(`Message`))
+	return err
+}
+
+type testDataRenderer struct{}
+
+func (testDataRenderer) Render(w io.Writer, name string, data interface{}, c Context) error {
+	_, err := fmt.Fprintf(w, `%s:%s`, data.(H).String(`user`), data.(H).String(`title`))
+	return err
+}
+
+func TestEchoAutoTemplateData(t *testing.T) {
+	e := New()
+	e.SetRenderer(testDataRenderer{})
+	e.SetAutoTemplateData(true)
+	e.Use(func(h Handler) HandlerFunc {
+		return func(c Context) error {
+			c.Set(`user`, `admin`)
+			c.Set(`title`, `default`)
+			return h.Handle(c)
+		}
+	})
+	e.Get("/", func(c Context) error {
+		return c.Render(`index`, H{`title`: `home`})
+	})
+	e.Get("/nil", func(c Context) error {
+		return c.Render(`index`, nil)
+	})
+	e.RebuildRouter()
+
+	c, b := request(GET, "/", e)
+	assert.Equal(t, http.StatusOK, c)
+	assert.Equal(t, `admin:home`, b)
+
+	c, b = request(GET, "/nil", e)
+	assert.Equal(t, http.StatusOK, c)
+	assert.Equal(t, `admin:default`, b)
+}
+
+func TestEchoCustomMethod(t *testing.T) {
+	e := New()
+	e.Add(`propfind`, "/dav/:name", func(c Context) error {
+		return c.String(c.Request().Method() + ` ` + c.Param(`name`))
+	})
+	e.Route(`get,mkcol`, "/dir", func(c Context) error {
+		return c.String(c.Request().Method())
+	})
+	e.RebuildRouter()
+
+	c, b := request(`PROPFIND`, "/dav/file.txt", e)
+	assert.Equal(t, http.StatusOK, c)
+	assert.Equal(t, `PROPFIND file.txt`, b)
+	c, b = request(`MKCOL`, "/dir", e)
+	assert.Equal(t, http.StatusOK, c)
+	assert.Equal(t, `MKCOL`, b)
+	c, _ = request(GET, "/dir", e)
+	assert.Equal(t, http.StatusOK, c)
+	c, _ = request(GET, "/dav/file.txt", e)
+	assert.Equal(t, http.StatusMethodNotAllowed, c)
+}
+
+func TestEchoRunURL(t *testing.T) {
+	// a unix socket, unlike a TCP port, can not be taken by another process
+	// before RunURL listens on it
+	dir, err := ioutil.TempDir(``, `echo-run`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	sock := filepath.Join(dir, `echo.sock`)
+
+	e := New()
+	// the handler runs after Run has set the engine, receiving from served
+	// orders Stop after it
+	served := make(chan struct{}, 1)
+	e.Get("/", func(c Context) error {
+		select {
+		case served <- struct{}{}:
+		default:
+		}
+		return c.String(`OK`)
+	})
+	errs := make(chan error, 1)
+	go func() {
+		errs <- e.RunURL(`unix://` + sock)
+	}()
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, `unix`, sock)
+		},
+	}}
+	var resp *http.Response
+	for i := 0; i < 50; i++ {
+		resp, err = client.Get(`http://localhost/`)
+		if err == nil {
+			break
+		}
+		select {
+		case err = <-errs:
+			t.Fatal(err)
+		case <-time.After(20 * time.Millisecond):
+		}
+	}
+	if assert.NoError(t, err) {
+		b, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		assert.Equal(t, `OK`, string(b))
+		<-served
+		assert.NoError(t, e.Stop())
+	}
+
+	assert.Error(t, New().RunURL(`ftp://127.0.0.1:0`))
+}
+
+func TestEchoServeHTTPRecoversPanic(t *testing.T) {
+	e := New()
+	e.Get("/panic", func(c Context) error {
+		c.Set(`dirty`, true)
+		panic(`boom`)
+	})
+	e.Get("/ok", func(c Context) error {
+		if c.Get(`dirty`) != nil {
+			return c.String(`dirty`)
+		}
+		return c.String(`OK`)
+	})
+	e.RebuildRouter()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if i%2 == 0 {
+				c, _ := request(GET, "/panic", e)
+				assert.Equal(t, http.StatusInternalServerError, c)
+				return
+			}
+			c, b := request(GET, "/ok", e)
+			assert.Equal(t, http.StatusOK, c)
+			assert.Equal(t, `OK`, b)
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestEchoPostResponseHook(t *testing.T) {
+	e := New()
+	var statuses []int
+	e.Use(func(h Handler) HandlerFunc {
+		return func(c Context) error {
+			c.AddPostResponseHook(func(c Context) {
+				assert.True(t, c.Response().Committed())
+				statuses = append(statuses, c.Response().Status())
+			})
+			return h.Handle(c)
+		}
+	})
+	e.Get("/ok", func(c Context) error {
+		return c.String(`OK`)
+	})
+	e.Get("/fail", func(c Context) error {
+		return NewHTTPError(http.StatusBadRequest, `invalid`)
+	})
+	e.Get("/panic", func(c Context) error {
+		panic(`boom`)
+	})
+	e.RebuildRouter()
+
+	c, _ := request(GET, "/ok", e)
+	assert.Equal(t, http.StatusOK, c)
+	c, _ = request(GET, "/fail", e)
+	assert.Equal(t, http.StatusBadRequest, c)
+	c, _ = request(GET, "/panic", e)
+	assert.Equal(t, http.StatusInternalServerError, c)
+	assert.Equal(t, []int{http.StatusOK, http.StatusBadRequest, http.StatusInternalServerError}, statuses)
+}
+
+func TestEchoPostResponseHookPanic(t *testing.T) {
+	e := New()
+	var ran bool
+	e.Get("/", func(c Context) error {
+		c.AddPostResponseHook(func(c Context) {
+			panic(`boom`)
+		})
+		c.AddPostResponseHook(func(c Context) {
+			ran = true
+		})
+		return c.String(`OK`)
+	})
+	e.RebuildRouter()
+
+	assert.NotPanics(t, func() {
+		c, b := request(GET, "/", e)
+		assert.Equal(t, http.StatusOK, c)
+		assert.Equal(t, `OK`, b)
+	})
+	assert.True(t, ran)
+}
+
+func TestEchoSPAFallback(t *testing.T) {
+	root, err := ioutil.TempDir(``, `echo-spa`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	index := filepath.Join(root, `index.html`)
+	ioutil.WriteFile(index, []byte(`<div id="app"></div>`), 0644)
+
+	e := New()
+	e.Get("/api/users", func(c Context) error {
+		return c.JSON(H{`users`: []string{}})
+	})
+	e.SPAFallback(index, `/api/`)
+	e.RebuildRouter()
+
+	c, b := request(GET, "/some/spa/route", e)
+	assert.Equal(t, http.StatusOK, c)
+	assert.Equal(t, `<div id="app"></div>`, b)
+
+	c, b = request(GET, "/api/users", e)
+	assert.Equal(t, http.StatusOK, c)
+	assert.Contains(t, b, `"users"`)
+
+	rec := test.Request(GET, "/api/missing", e)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Contains(t, rec.Header().Get(HeaderContentType), MIMEApplicationJSON)
+
+	c, _ = request(GET, "/assets/app.js", e)
+	assert.Equal(t, http.StatusNotFound, c)
+	c, b = request(GET, "/v1.2/route", e)
+	assert.Equal(t, http.StatusOK, c)
+	assert.Equal(t, `<div id="app"></div>`, b)
+
+	assert.Panics(t, func() { New().SPAFallback(index, `/`) })
+}
+
+func TestEchoProblemJSON(t *testing.T) {
+	e := New()
+	e.UseProblemJSON(true)
+	e.Get("/items/:id", func(c Context) error {
+		return NewHTTPError(http.StatusNotFound, `item not found`)
+	})
+	e.Get("/fail", func(c Context) error {
+		return errors.New(`database is down`)
+	})
+	e.RebuildRouter()
+
+	rec := test.Request(GET, "/items/1", e)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, MIMEApplicationProblemJSON, rec.Header().Get(HeaderContentType))
+	assert.Equal(t, `{"type":"about:blank","title":"Not Found","status":404,"detail":"item not found","instance":"/items/1"}`, rec.Body.String())
+
+	rec = test.Request(GET, "/fail", e)
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, `{"type":"about:blank","title":"Internal Server Error","status":500,"detail":"Internal Server Error","instance":"/fail"}`, rec.Body.String())
+
+	e.SetDebug(true)
+	rec = test.Request(GET, "/fail", e)
+	assert.Equal(t, `{"type":"about:blank","title":"Internal Server Error","status":500,"detail":"database is down","instance":"/fail"}`, rec.Body.String())
+}
+
+func TestEchoDefaultCharset(t *testing.T) {
+	e := New()
+	e.SetRenderer(testRenderer{})
+	e.Get("/string", func(c Context) error {
+		return c.String(`OK`)
+	})
+	e.Get("/html", func(c Context) error {
+		return c.HTML(`<b>OK</b>`)
+	})
+	e.Get("/json", func(c Context) error {
+		return c.JSON(H{`ok`: true})
+	})
+	e.Get("/xml", func(c Context) error {
+		return c.XML(H{`ok`: true})
+	})
+	e.Get("/render", func(c Context) error {
+		return c.Render(`index`, H{`Message`: `OK`})
+	})
+	e.RebuildRouter()
+
+	expected := map[string]string{
+		"/string": MIMETextPlain,
+		"/html":   MIMETextHTML,
+		"/json":   MIMEApplicationJSON,
+		"/xml":    MIMEApplicationXML,
+		"/render": MIMETextHTML,
+	}
+	for path, mime := range expected {
+		rec := test.Request(GET, path, e)
+		assert.Equal(t, mime+`; charset=utf-8`, rec.Header().Get(HeaderContentType), path)
+	}
+
+	e.SetDefaultCharset(`gbk`)
+	for path, mime := range expected {
+		rec := test.Request(GET, path, e)
+		assert.Equal(t, mime+`; charset=gbk`, rec.Header().Get(HeaderContentType), path)
+	}
+
+	e.SetDefaultCharset(``)
+	rec := test.Request(GET, "/string", e)
+	assert.Equal(t, MIMETextPlain, rec.Header().Get(HeaderContentType))
+}
+
+func TestEchoErrorTemplate(t *testing.T) {
+	e := New()
+	e.SetErrorTemplate(http.StatusNotFound, `errors/404.html`)
+	e.RebuildRouter()
+
+	// No renderer: plain text fallback
+	c, b := request(GET, "/missing", e)
+	assert.Equal(t, http.StatusNotFound, c)
+	assert.Equal(t, "Not Found", b)
+
+	e.SetRenderer(testRenderer{})
+	rec := test.Request(GET, "/missing", e, func(req *http.Request) {
+		req.Header.Set(HeaderAccept, MIMETextHTML)
+	})
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, "errors/404.html:Not Found", rec.Body.String())
+	assert.Equal(t, MIMETextHTMLCharsetUTF8, rec.Header().Get(HeaderContentType))
+
+	rec = test.Request(GET, "/missing", e, func(req *http.Request) {
+		req.Header.Set(HeaderAccept, MIMEApplicationJSON)
+	})
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, "Not Found", rec.Body.String())
+}
+
+type recordLogger struct {
+	logger.Base
+	entries  []string
+	warnings []string
+	fields   map[string]interface{}
+}
+
+func (l *recordLogger) Warn(args ...interface{}) {
+	l.warnings = append(l.warnings, fmt.Sprint(args...))
+}
+
+func (l *recordLogger) Warnf(format string, args ...interface{}) {
+	l.warnings = append(l.warnings, fmt.Sprintf(format, args...))
+}
+
+func (l *recordLogger) Debug(args ...interface{}) {
+	l.entries = append(l.entries, fmt.Sprint(args...))
+}
+
+func (l *recordLogger) Debugf(format string, args ...interface{}) {
+	l.entries = append(l.entries, fmt.Sprintf(format, args...))
+}
+
+type recordFieldsLogger struct {
+	recordLogger
+}
+
+func (l *recordFieldsLogger) WithFields(fields map[string]interface{}) logger.Logger {
+	l.fields = fields
+	return l
+}
+
+func TestEchoErrorLogContext(t *testing.T) {
+	e := New()
+	l := &recordLogger{}
+	e.SetLogger(l)
+	e.Post("/users/:id", func(c Context) error {
+		return NewHTTPError(http.StatusConflict, "conflict")
+	})
+	e.RebuildRouter()
+
+	rec := test.Request(POST, "/users/1", e, func(req *http.Request) {
+		req.Header.Set(HeaderXRequestID, "rid-1")
+	})
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	if assert.Len(t, l.entries, 1) {
+		assert.Contains(t, l.entries[0], "POST /users/1")
+		assert.Contains(t, l.entries[0], "status=409")
+		assert.Contains(t, l.entries[0], "request_id=rid-1")
+	}
+
+	// structured fields
+	fl := &recordFieldsLogger{}
+	e.SetLogger(fl)
+	test.Request(POST, "/users/2", e)
+	if assert.Len(t, fl.entries, 1) {
+		assert.Contains(t, fl.entries[0], "conflict")
+	}
+	assert.Equal(t, "POST", fl.fields["method"])
+	assert.Equal(t, "/users/2", fl.fields["path"])
+	assert.Equal(t, http.StatusConflict, fl.fields["status"])
+	assert.NotContains(t, fl.fields, "request_id")
+}
+
+type hijackRecorder struct {
+	*httptest.ResponseRecorder
+}
+
+func (r *hijackRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, peer := net.Pipe()
+	peer.Close()
+	return conn, bufio.NewReadWriter(bufio.NewReader(conn), bufio.NewWriter(conn)), nil
+}
+
+func TestEchoHijackedContextNotPooled(t *testing.T) {
+	e := New()
+	var hijacked, normal Context
+	e.Get("/ws", func(c Context) error {
+		hijacked = c
+		return c.Response().Hijacker(func(net.Conn) {})
+	})
+	e.Get("/", func(c Context) error {
+		normal = c
+		return c.String("OK")
+	})
+	e.RebuildRouter()
+
+	req := test.NewStdRequest(GET, "/ws")
+	res := test.WrapResponse(req, &hijackRecorder{httptest.NewRecorder()})
+	e.ServeHTTP(test.WrapRequest(req), res)
+	assert.True(t, engine.IsHijacked(res))
+
+	for i := 0; i < 3; i++ {
+		rec := test.Request(GET, "/", e)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.NotSame(t, hijacked, normal)
+	}
+	assert.Equal(t, "/ws", hijacked.Request().URL().Path())
+}
+
+func TestEchoDefaultHeader(t *testing.T) {
+	e := New()
+	e.SetDefaultHeader("server", "echo").SetDefaultHeader("X-Powered-By", "Go")
+	e.Get("/", func(c Context) error {
+		return c.String("OK")
+	})
+	e.Get("/override", func(c Context) error {
+		c.Response().Header().Set(HeaderServer, "custom")
+		c.Response().Header().Del("X-Powered-By")
+		return c.String("OK")
+	})
+	e.RebuildRouter()
+
+	rec := test.Request(GET, "/", e)
+	assert.Equal(t, "echo", rec.Header().Get(HeaderServer))
+	assert.Equal(t, "Go", rec.Header().Get("X-Powered-By"))
+
+	rec = test.Request(GET, "/override", e)
+	assert.Equal(t, "custom", rec.Header().Get(HeaderServer))
+	_, ok := rec.Header()["X-Powered-By"]
+	assert.False(t, ok)
+
+	e.RemoveDefaultHeader("X-POWERED-BY")
+	rec = test.Request(GET, "/", e)
+	assert.Equal(t, "echo", rec.Header().Get(HeaderServer))
+	assert.Empty(t, rec.Header().Get("X-Powered-By"))
+}
+
+func TestEchoRouteConflictPolicy(t *testing.T) {
+	newEcho := func(policy RouteConflictPolicy) (*Echo, *recordLogger) {
+		e := New()
+		l := &recordLogger{}
+		e.SetLogger(l)
+		e.SetRouteConflictPolicy(policy)
+		e.Get("/users", func(c Context) error {
+			return c.String("first")
+		})
+		e.Get("/users", func(c Context) error {
+			return c.String("second")
+		})
+		e.Post("/users", func(c Context) error {
+			return c.String("post")
+		})
+		return e, l
+	}
+
+	e, _ := newEcho(RouteConflictIgnore)
+	e.RebuildRouter()
+	assert.Len(t, e.Routes(), 3)
+	_, b := request(GET, "/users", e)
+	assert.Equal(t, "second", b)
+
+	e, l := newEcho(RouteConflictWarn)
+	e.RebuildRouter()
+	assert.Len(t, e.Routes(), 3)
+	if assert.Len(t, l.warnings, 1) {
+		assert.Contains(t, l.warnings[0], "GET /users")
+	}
+
+	e, _ = newEcho(RouteConflictLastWins)
+	e.RebuildRouter()
+	assert.Len(t, e.Routes(), 2)
+	_, b = request(GET, "/users", e)
+	assert.Equal(t, "second", b)
+	_, b = request(POST, "/users", e)
+	assert.Equal(t, "post", b)
+
+	e, _ = newEcho(RouteConflictPanic)
+	assert.PanicsWithError(t, "echo: route GET /users is registered more than once", func() {
+		e.RebuildRouter()
+	})
+
+	e = New()
+	e.SetRouteConflictPolicy(RouteConflictPanic)
+	e.Get("/users/:id", func(c Context) error {
+		return c.String("id")
+	})
+	e.Get("/users/:uid", func(c Context) error {
+		return c.String("uid")
+	})
+	assert.PanicsWithError(t, "echo: route GET /users/:uid is registered more than once", func() {
+		e.RebuildRouter()
+	})
+
+	e = New()
+	e.SetRouteConflictPolicy(RouteConflictLastWins)
+	e.Get("/users/:id", func(c Context) error {
+		return c.String("first")
+	})
+	e.RebuildRouter()
+	other := New()
+	other.Get("/users/:uid", func(c Context) error {
+		return c.String("appended")
+	})
+	e.AppendRouter(other.Routes())
+	assert.Len(t, e.Routes(), 1)
+	_, b = request(GET, "/users/1", e)
+	assert.Equal(t, "appended", b)
+
+	e = New()
+	e.SetRouteConflictPolicy(RouteConflictPanic)
+	e.Get("/users/:id", func(c Context) error {
+		return c.String("first")
+	})
+	e.RebuildRouter()
+	other = New()
+	other.Get("/posts/:id", func(c Context) error {
+		return c.String("posts")
+	})
+	e.AppendRouter(other.Routes())
+	assert.Len(t, e.Routes(), 2)
+	_, b = request(GET, "/posts/1", e)
+	assert.Equal(t, "posts", b)
+	other = New()
+	other.Get("/users/:uid", func(c Context) error {
+		return c.String("appended")
+	})
+	assert.PanicsWithError(t, "echo: route GET /users/:uid is registered more than once", func() {
+		e.AppendRouter(other.Routes())
+	})
+}
+
+func TestEchoRebuildRouterHosts(t *testing.T) {
+	e := New()
+	e.Host(`a.example.com`).Get("/host", func(c Context) error {
+		return c.String(`host`)
+	})
+	e.RebuildRouter()
+	requestHost := func() (int, string) {
+		return request(GET, "/host", e, func(req *http.Request) {
+			req.Host = `a.example.com`
+		})
+	}
+	c, b := requestHost()
+	assert.Equal(t, http.StatusOK, c)
+	assert.Equal(t, `host`, b)
+
+	// the host routes are rebuilt from the routes given, not kept
+	e.RebuildRouter([]*Route{})
+	c, _ = requestHost()
+	assert.Equal(t, http.StatusNotFound, c)
+}
+
+func TestEchoRouteAlias(t *testing.T) {
+	e := New()
+	e.Get("/v2/users/:id", func(c Context) error {
+		return c.String(c.Param("id") + " " + c.Path())
+	}).SetName("user").Alias("/v1/users/:id", "/users/:id")
+	e.RebuildRouter()
+
+	for _, path := range []string{"/v2/users/1", "/v1/users/1", "/users/1"} {
+		code, body := request(GET, path, e)
+		assert.Equal(t, http.StatusOK, code, path)
+		assert.Equal(t, "1 /v2/users/:id", body, path)
+	}
+	assert.Equal(t, "/v2/users/2", e.URI("user", 2))
+	code, _ := request(GET, "/v3/users/1", e)
+	assert.Equal(t, http.StatusNotFound, code)
+}
+
+func TestEchoFormatExtension(t *testing.T) {
+	e := New()
+	e.SetFormatExtension(true)
+	e.Get("/users/:id", func(c Context) error {
+		user := &struct {
+			ID int `param:"id" json:"id"`
+		}{}
+		if err := c.BindParams(user); err != nil {
+			return err
+		}
+		if c.Format() != `json` {
+			return c.String(c.Request().URL().Path())
+		}
+		return c.JSON(user)
+	})
+	e.RebuildRouter()
+
+	rec := test.Request(GET, "/users/42.json", e)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `{"id":42}`, rec.Body.String())
+	assert.Contains(t, rec.Header().Get(HeaderContentType), MIMEApplicationJSON)
+
+	rec = test.Request(GET, "/users/42.txt", e)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = test.Request(GET, "/users/42", e, func(req *http.Request) {
+		req.Header.Set(HeaderAccept, MIMETextHTML)
+	})
+	assert.Equal(t, "/users/42", rec.Body.String())
+
+	// the router honors the per-context setting of a pre middleware
+	e = New()
+	e.Pre(func(next HandlerFunc) HandlerFunc {
+		return func(c Context) error {
+			c.WithFormatExtension(c.Query(`ext`) == `1`)
+			return next(c)
+		}
+	})
+	e.Get("/users/:id", func(c Context) error {
+		return c.String(c.Param(`id`) + ` ` + c.Format())
+	})
+	e.RebuildRouter()
+	_, b := request(GET, "/users/42.json?ext=1", e)
+	assert.Equal(t, `42 json`, b)
+	_, b = request(GET, "/users/42.json", e)
+	assert.Equal(t, `42.json html`, b)
+}
+
+func TestEchoRequireResponse(t *testing.T) {
+	e := New()
+	l := &recordLogger{}
+	e.SetLogger(l)
+	e.Get("/noop", func(c Context) error {
+		return nil
+	})
+	e.Get("/ok", func(c Context) error {
+		return c.NoContent(http.StatusNoContent)
+	})
+	e.RebuildRouter()
+
+	rec := test.Request(GET, "/noop", e)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, l.warnings)
+
+	e.SetRequireResponse(true)
+	rec = test.Request(GET, "/noop", e)
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	if assert.Len(t, l.warnings, 1) {
+		assert.Contains(t, l.warnings[0], "GET /noop")
+	}
+
+	rec = test.Request(GET, "/ok", e)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Len(t, l.warnings, 1)
+}
+
+func userHandler(c Context) error {
+	return c.String("user")
+}
+
+func TestRouteHandlerName(t *testing.T) {
+	e := New()
+	e.Use(mw.Log())
+	e.Get("/users", userHandler, func(h Handler) HandlerFunc {
+		return func(c Context) error {
+			return h.Handle(c)
+		}
+	}).SetName("users")
+	e.RebuildRouter()
+
+	r := e.Routes()[0]
+	assert.Equal(t, "users", r.Name)
+	assert.Equal(t, "github.com/webx-top/echo_test.userHandler", r.HandlerName())
+	assert.Equal(t, HandlerName(userHandler), HandlerName(r.RawHandler()))
+	assert.NotEqual(t, r.HandlerName(), HandlerName(r.Handler))
+}
+
+// drainEngine is an engine whose Shutdown waits for the deadline, as when a
+// request does not finish within the graceful period.
+type drainEngine struct{}
+
+func (drainEngine) SetHandler(engine.Handler) {}
+func (drainEngine) SetLogger(logger.Logger)   {}
+func (drainEngine) Start() error              { return nil }
+func (drainEngine) Stop() error               { return nil }
+func (drainEngine) Shutdown(ctx context.Context) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestEchoShutdown(t *testing.T) {
+	e := New()
+	started := make(chan struct{}, 1)
+	e.Get("/slow", func(c Context) error {
+		started <- struct{}{}
+		<-c.Done()
+		return c.String("shutting down")
+	})
+	assert.NoError(t, e.Run(drainEngine{}))
+
+	served := make(chan *httptest.ResponseRecorder, 1)
+	go func() {
+		served <- test.Request(GET, "/slow", e)
+	}()
+	<-started
+	ctx, cancel := context.WithCancel(context.Background())
+	shutdown := make(chan error, 1)
+	go func() {
+		shutdown <- e.Shutdown(ctx)
+	}()
+
+	// the in-flight request is cancelled as soon as Shutdown is called,
+	// long before the end of the graceful period
+	select {
+	case rec := <-served:
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "shutting down", rec.Body.String())
+	case <-time.After(time.Second):
+		t.Fatal("in-flight request was not cancelled by Shutdown")
+	}
+	select {
+	case <-e.ShutdownSignal():
+	default:
+		t.Fatal("shutdown signal not closed by Shutdown")
+	}
+	cancel()
+	assert.Equal(t, context.Canceled, <-shutdown)
+
+	// a new start is not cancelled
+	assert.NoError(t, e.Run(drainEngine{}))
+	select {
+	case <-e.ShutdownSignal():
+		t.Fatal("shutdown signal not reset by Run")
+	default:
+	}
+}
+
+func TestEchoUseInvalidMiddleware(t *testing.T) {
+	e := New()
+	assert.PanicsWithError(t, `invalid middleware: "log" (string), expected an echo.Middleware, a func(echo.Handler) echo.Handler, a handler func or an http.Handler`, func() {
+		e.Use("log")
+	})
+	for _, m := range []interface{}{nil, MiddlewareFunc(nil), 1} {
+		func() {
+			defer func() {
+				err, ok := recover().(error)
+				assert.True(t, ok, "%#v", m)
+				assert.True(t, errors.Is(err, ErrInvalidMiddleware), "%v", err)
+			}()
+			e.Group("/admin").Use(m)
+		}()
+	}
+}
@@ -15,22 +15,38 @@ import (
 
 // Config defines engine configuration.
 type Config struct {
-	Address            string       // TCP address to listen on.
-	Listener           net.Listener // Custom `net.Listener`. If set, server accepts connections on it.
-	ReusePort          bool
-	TLSAuto            bool
-	TLSHosts           []string
-	TLSEmail           string
-	TLSCacheDir        string
-	TLSConfig          *tls.Config
-	TLSCertFile        string        // TLS certificate file path.
-	TLSKeyFile         string        // TLS key file path.
-	DisableHTTP2       bool          // Disables HTTP/2.
-	ReadTimeout        time.Duration // Maximum duration before timing out read of the request.
-	WriteTimeout       time.Duration // Maximum duration before timing out write of the response.
-	MaxConnsPerIP      int
-	MaxRequestsPerConn int
-	MaxRequestBodySize int
+	Address              string       // TCP address to listen on.
+	Listener             net.Listener // Custom `net.Listener`. If set, server accepts connections on it.
+	ReusePort            bool
+	TLSAuto              bool
+	TLSHosts             []string
+	TLSEmail             string
+	TLSCacheDir          string
+	TLSConfig            *tls.Config
+	TLSCertFile          string        // TLS certificate file path.
+	TLSKeyFile           string        // TLS key file path.
+	DisableHTTP2         bool          // Disables HTTP/2.
+	ReadTimeout          time.Duration // Maximum duration before timing out read of the request.
+	WriteTimeout         time.Duration // Maximum duration before timing out write of the response.
+	MaxConnsPerIP        int
+	MaxRequestsPerConn   int
+	MaxRequestBodySize   int
+	MaxRequestURILength  int           // Maximum length of the request URI. Longer requests are rejected with 414.
+	MaxRequestHeaderSize int           // Maximum size in bytes of the request header block, enforced by the engine (`http.Server.MaxHeaderBytes` or the fasthttp read buffer size). Larger requests are rejected with 431.
+	MaxRequestHeaders    int           // Maximum number of the request header fields. Requests with more are rejected with 431.
+	IdleTimeout          time.Duration // Maximum duration to wait for the next request on a keep-alive connection.
+}
+
+// CheckRequestLimits returns the HTTP status code used to reject a request
+// whose URI or header fields exceed the configured limits, or 0 if it is acceptable.
+func (c *Config) CheckRequestLimits(uriLength int, headerCount int) int {
+	if c.MaxRequestURILength > 0 && uriLength > c.MaxRequestURILength {
+		return http.StatusRequestURITooLong
+	}
+	if c.MaxRequestHeaders > 0 && headerCount > c.MaxRequestHeaders {
+		return http.StatusRequestHeaderFieldsTooLarge
+	}
+	return 0
 }
 
 //usage:
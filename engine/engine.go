@@ -193,6 +193,12 @@ type (
 		ServeHTTP(Request, Response)
 	}
 
+	// Hijackable is implemented by the responses which report whether the
+	// connection has been hijacked by the handler.
+	Hijackable interface {
+		Hijacked() bool
+	}
+
 	// HandlerFunc is an adapter to allow the use of `func(Request, Response)` as HTTP handlers.
 	HandlerFunc func(Request, Response)
 )
@@ -201,3 +207,23 @@ type (
 func (h HandlerFunc) ServeHTTP(req Request, res Response) {
 	h(req, res)
 }
+
+// IsHijacked reports whether the connection of the response has been hijacked,
+// the response and its context must not be reused in that case.
+func IsHijacked(res Response) bool {
+	h, ok := res.(Hijackable)
+	return ok && h.Hijacked()
+}
+
+// HeaderCount returns the number of the header fields, a key with several
+// values counts once per value. It uses the `Len() int` method of the engine
+// header when there is one, to avoid converting the header with `Std()`.
+func HeaderCount(h Header) (count int) {
+	if l, ok := h.(interface{ Len() int }); ok {
+		return l.Len()
+	}
+	for _, values := range h.Std() {
+		count += len(values)
+	}
+	return
+}
@@ -4,4 +4,7 @@ import "errors"
 
 var (
 	ErrUnsupported = errors.New(`Unsupported`)
+	// ErrReusePortUnsupported is returned by NewListener when SO_REUSEPORT is
+	// requested on a platform without support and ReusePortFallback is false.
+	ErrReusePortUnsupported = errors.New(`SO_REUSEPORT is not supported on this platform`)
 )
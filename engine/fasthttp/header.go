@@ -41,6 +41,11 @@ func (h *RequestHeader) Object() interface{} {
 	return h.header
 }
 
+// Len returns the number of the header fields, see `engine.HeaderCount`.
+func (h *RequestHeader) Len() int {
+	return h.header.Len()
+}
+
 func (h *ResponseHeader) Add(key, val string) {
 	h.header.Set(key, val)
 }
@@ -77,6 +82,11 @@ func (h *ResponseHeader) Object() interface{} {
 	return h.header
 }
 
+// Len returns the number of the header fields, see `engine.HeaderCount`.
+func (h *ResponseHeader) Len() int {
+	return h.header.Len()
+}
+
 func (h *ResponseHeader) reset(hdr *fasthttp.ResponseHeader) {
 	h.header = hdr
 }
@@ -28,6 +28,7 @@ type Response struct {
 	writer            io.Writer
 	logger            logger.Logger
 	stdResponseWriter http.ResponseWriter
+	hijacked          bool
 }
 
 func NewResponse(c *fasthttp.RequestCtx) *Response {
@@ -95,9 +96,15 @@ func (r *Response) Writer() io.Writer {
 func (r *Response) Hijacker(fn func(net.Conn)) error {
 	r.RequestCtx.Hijack(fasthttp.HijackHandler(fn))
 	r.committed = true
+	r.hijacked = true
 	return nil
 }
 
+// Hijacked reports whether the connection has been hijacked.
+func (r *Response) Hijacked() bool {
+	return r.hijacked
+}
+
 func (r *Response) Body() []byte {
 	switch strings.ToLower(r.header.Get(`Content-Encoding`)) {
 	case `gzip`:
@@ -161,6 +168,7 @@ func (r *Response) reset(c *fasthttp.RequestCtx, h engine.Header) {
 	r.committed = false
 	r.writer = c
 	r.stdResponseWriter = nil
+	r.hijacked = false
 }
 
 func (r *Response) StdResponseWriter() http.ResponseWriter {
@@ -207,8 +215,8 @@ func (w *netHTTPResponseWriter) WriteHeader(statusCode int) {
 }
 
 func (w *netHTTPResponseWriter) Write(b []byte) (int, error) {
-	if w.response.committed {
-		return 0, ErrAlreadyCommitted
+	if !w.response.committed {
+		w.WriteHeader(w.StatusCode())
 	}
 	return w.response.Write(b)
 }
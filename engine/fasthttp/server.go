@@ -4,6 +4,7 @@ package fasthttp
 
 import (
 	"context"
+	"net/http"
 	"sync"
 
 	"github.com/admpub/fasthttp"
@@ -49,9 +50,11 @@ func NewWithConfig(c *engine.Config) (s *Server) {
 		Server: &fasthttp.Server{
 			ReadTimeout:        c.ReadTimeout,
 			WriteTimeout:       c.WriteTimeout,
+			IdleTimeout:        c.IdleTimeout,
 			MaxConnsPerIP:      c.MaxConnsPerIP,
 			MaxRequestsPerConn: c.MaxRequestsPerConn,
 			MaxRequestBodySize: c.MaxRequestBodySize,
+			ReadBufferSize:     c.MaxRequestHeaderSize,
 		},
 		config: c,
 		pool: &pool{
@@ -87,6 +90,12 @@ func NewWithConfig(c *engine.Config) (s *Server) {
 		logger: log.GetLogger("echo"),
 	}
 	s.Handler = s.ServeHTTP
+	if c.MaxRequestBodySize > 0 {
+		// Reject `Expect: 100-continue` uploads with 417 before the body is sent.
+		s.ContinueHandler = func(h *fasthttp.RequestHeader) bool {
+			return h.ContentLength() <= c.MaxRequestBodySize
+		}
+	}
 	return
 }
 
@@ -98,6 +107,11 @@ func (s *Server) SetLogger(l logger.Logger) {
 	s.logger = l
 }
 
+// Config returns the server config.
+func (s *Server) Config() *engine.Config {
+	return s.config
+}
+
 // Start implements `engine.Server#Start` function.
 func (s *Server) Start() error {
 	if s.config.Listener == nil {
@@ -131,6 +145,11 @@ func (s *Server) Shutdown(ctx context.Context) error {
 }
 
 func (s *Server) ServeHTTP(c *fasthttp.RequestCtx) {
+	if code := s.config.CheckRequestLimits(len(c.RequestURI()), c.Request.Header.Len()); code != 0 {
+		c.Error(http.StatusText(code), code)
+		return
+	}
+
 	// Request
 	req := s.pool.request.Get().(*Request)
 	reqHdr := s.pool.requestHeader.Get().(*RequestHeader)
@@ -147,6 +166,10 @@ func (s *Server) ServeHTTP(c *fasthttp.RequestCtx) {
 	req.reset(res, c, reqHdr, reqURL)
 
 	s.handler.ServeHTTP(req, res)
+	if res.hijacked {
+		// the hijacked connection may still use them
+		return
+	}
 
 	s.pool.request.Put(req)
 	s.pool.requestHeader.Put(reqHdr)
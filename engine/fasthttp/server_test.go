@@ -0,0 +1,125 @@
+//go:build !appengine
+// +build !appengine
+
+package fasthttp
+
+import (
+	"bufio"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/webx-top/echo"
+	"github.com/webx-top/echo/engine"
+)
+
+func TestServerFile(t *testing.T) {
+	dir, err := ioutil.TempDir(``, `echo-fasthttp`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, `doc.txt`)
+	ioutil.WriteFile(file, []byte(`0123456789`), 0644)
+
+	ln, err := net.Listen(`tcp`, `127.0.0.1:0`)
+	if !assert.NoError(t, err) {
+		return
+	}
+	s := NewWithConfig(&engine.Config{Address: ln.Addr().String(), Listener: ln})
+	e := echo.New()
+	e.Get("/doc.txt", func(c echo.Context) error {
+		return c.File(file)
+	})
+	go e.Run(s)
+	defer s.Stop()
+
+	get := func(rangeHeader string) (int, string) {
+		req, _ := http.NewRequest(http.MethodGet, `http://`+ln.Addr().String()+`/doc.txt`, nil)
+		if len(rangeHeader) > 0 {
+			req.Header.Set(`Range`, rangeHeader)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		defer resp.Body.Close()
+		b, _ := ioutil.ReadAll(resp.Body)
+		return resp.StatusCode, string(b)
+	}
+
+	code, body := get(``)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, `0123456789`, body)
+
+	code, body = get(`bytes=2-5`)
+	assert.Equal(t, http.StatusPartialContent, code)
+	assert.Equal(t, `2345`, body)
+}
+
+func TestServerIdleTimeout(t *testing.T) {
+	ln, err := net.Listen(`tcp`, `127.0.0.1:0`)
+	if !assert.NoError(t, err) {
+		return
+	}
+	s := NewWithConfig(&engine.Config{
+		Address:     ln.Addr().String(),
+		Listener:    ln,
+		ReadTimeout: time.Second,
+		IdleTimeout: 200 * time.Millisecond,
+	})
+	s.SetHandler(engine.HandlerFunc(func(req engine.Request, res engine.Response) {
+		res.Write([]byte(`OK`))
+	}))
+	go s.Start()
+	defer s.Stop()
+
+	dial := func() (net.Conn, *bufio.Reader) {
+		conn, err := net.Dial(`tcp`, ln.Addr().String())
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		return conn, bufio.NewReader(conn)
+	}
+	get := func(conn net.Conn, r *bufio.Reader) error {
+		if _, err := conn.Write([]byte("GET / HTTP/1.1\r\nHost: localhost\r\n\r\n")); err != nil {
+			return err
+		}
+		resp, err := http.ReadResponse(r, nil)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		_, err = ioutil.ReadAll(resp.Body)
+		return err
+	}
+
+	// an active connection stays open beyond the idle timeout
+	active, activeReader := dial()
+	defer active.Close()
+	for i := 0; i < 6; i++ {
+		if !assert.NoError(t, get(active, activeReader), `request %d`, i) {
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	// an idle connection is closed by the server after the idle timeout
+	idle, idleReader := dial()
+	defer idle.Close()
+	if !assert.NoError(t, get(idle, idleReader)) {
+		return
+	}
+	start := time.Now()
+	idle.SetReadDeadline(start.Add(5 * time.Second))
+	_, err = idleReader.ReadByte()
+	assert.Equal(t, io.EOF, err)
+	assert.True(t, time.Since(start) < 5*time.Second)
+}
@@ -2,10 +2,18 @@ package engine
 
 import (
 	"net"
+	"runtime"
 	"strings"
 	"time"
+
+	"github.com/admpub/log"
 )
 
+// ReusePortFallback makes NewListener fall back to a normal listener, with a
+// warning, when SO_REUSEPORT is requested on a platform without support.
+// When false, ErrReusePortUnsupported is returned instead.
+var ReusePortFallback = true
+
 // tcpKeepAliveListener sets TCP keep-alive timeouts on accepted
 // connections. It's used by ListenAndServe and ListenAndServeTLS so
 // dead TCP connections (e.g. closing laptop mid-download) eventually
@@ -34,6 +42,13 @@ func NewListener(address string, reuse bool) (net.Listener, error) {
 		scheme = address[0:pos]
 		address = address[pos+len(delim):]
 	}
+	if reuse && !reusePortSupported {
+		if !ReusePortFallback {
+			return nil, ErrReusePortUnsupported
+		}
+		log.Warnf(`SO_REUSEPORT is not supported on %s/%s, falling back to a normal listener`, runtime.GOOS, runtime.Version())
+		reuse = false
+	}
 	l, err := newListener(scheme, address, reuse)
 	if err != nil {
 		return nil, err
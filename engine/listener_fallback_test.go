@@ -0,0 +1,30 @@
+// +build !go1.11 !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd
+
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewListenerReusePortFallback(t *testing.T) {
+	defer func(fallback bool) {
+		ReusePortFallback = fallback
+	}(ReusePortFallback)
+
+	ReusePortFallback = true
+	ln, err := NewListener(`127.0.0.1:0`, true)
+	if assert.NoError(t, err) {
+		ln.Close()
+	}
+
+	ReusePortFallback = false
+	_, err = NewListener(`127.0.0.1:0`, true)
+	assert.Equal(t, ErrReusePortUnsupported, err)
+
+	ln, err = NewListener(`127.0.0.1:0`, false)
+	if assert.NoError(t, err) {
+		ln.Close()
+	}
+}
@@ -1,5 +1,7 @@
 package engine
 
+import "net/http"
+
 var engines = map[string]func(*Config) Engine{}
 
 func Register(name string, newEngine func(*Config) Engine) {
@@ -13,3 +15,20 @@ func Get(name string) func(*Config) Engine {
 func New(name string, config *Config) Engine {
 	return Get(name)(config)
 }
+
+var stdWrapper func(*http.Request, http.ResponseWriter) (Request, Response)
+
+// RegisterStdWrapper registers the function which wraps `*http.Request` and
+// `http.ResponseWriter` into `engine.Request` and `engine.Response`.
+func RegisterStdWrapper(wrapper func(*http.Request, http.ResponseWriter) (Request, Response)) {
+	stdWrapper = wrapper
+}
+
+// WrapStd wraps `*http.Request` and `http.ResponseWriter` with the registered wrapper.
+func WrapStd(req *http.Request, w http.ResponseWriter) (Request, Response, error) {
+	if stdWrapper == nil {
+		return nil, nil, ErrUnsupported
+	}
+	ereq, eres := stdWrapper(req, w)
+	return ereq, eres, nil
+}
@@ -0,0 +1,7 @@
+// +build go1.11
+// +build darwin dragonfly freebsd linux netbsd openbsd
+
+package engine
+
+// reusePortSupported reports whether SO_REUSEPORT listeners are available.
+const reusePortSupported = true
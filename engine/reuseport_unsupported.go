@@ -0,0 +1,6 @@
+// +build !go1.11 !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd
+
+package engine
+
+// reusePortSupported reports whether SO_REUSEPORT listeners are available.
+const reusePortSupported = false
@@ -17,3 +17,8 @@ func (h *Header) Object() interface{} {
 func (h *Header) Std() http.Header {
 	return h.Header
 }
+
+// Len returns the number of the header fields, see `engine.HeaderCount`.
+func (h *Header) Len() int {
+	return headerCount(h.Header)
+}
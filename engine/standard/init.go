@@ -1,6 +1,10 @@
 package standard
 
 import (
+	"net/http"
+
+	"github.com/admpub/log"
+
 	"github.com/webx-top/echo/engine"
 )
 
@@ -10,4 +14,11 @@ func init() {
 	engine.Register(Name, func(c *engine.Config) engine.Engine {
 		return NewWithConfig(c)
 	})
+	engine.RegisterStdWrapper(WrapStd)
+}
+
+// WrapStd wraps `*http.Request` and `http.ResponseWriter` into `engine.Request`
+// and `engine.Response`.
+func WrapStd(req *http.Request, w http.ResponseWriter) (engine.Request, engine.Response) {
+	return NewRequest(req), NewResponse(w, req, log.GetLogger("echo"))
 }
@@ -4,7 +4,6 @@ import (
 	"io"
 	"io/ioutil"
 	"mime/multipart"
-	"net"
 	"net/http"
 	"strings"
 
@@ -67,14 +66,12 @@ func (r *Request) RealIP() string {
 	if len(r.realIP) > 0 {
 		return r.realIP
 	}
-	r.realIP = r.RemoteAddress()
 	if ip := r.header.Get(echo.HeaderXForwardedFor); len(ip) > 0 {
-		ip = strings.TrimSpace(strings.SplitN(ip, ",", 2)[0])
-		r.realIP = ip
+		r.realIP = engine.AddressIP(strings.SplitN(ip, ",", 2)[0])
 	} else if ip := r.header.Get(echo.HeaderXRealIP); len(ip) > 0 {
-		r.realIP = ip
+		r.realIP = engine.AddressIP(ip)
 	} else {
-		r.realIP, _, _ = net.SplitHostPort(r.realIP)
+		r.realIP = engine.AddressIP(r.RemoteAddress())
 	}
 	return r.realIP
 }
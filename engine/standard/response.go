@@ -22,17 +22,20 @@ type Response struct {
 	logger         logger.Logger
 	body           []byte
 	keepBody       bool
+	hijacked       bool
 	responseWriter *responseWriter
 }
 
 func NewResponse(w http.ResponseWriter, r *http.Request, l logger.Logger) *Response {
-	return &Response{
+	res := &Response{
 		ResponseWriter: w,
 		request:        r,
 		header:         &Header{Header: w.Header()},
 		writer:         w,
 		logger:         l,
 	}
+	res.responseWriter = &responseWriter{res}
+	return res
 }
 
 func (r *Response) Header() engine.Header {
@@ -112,11 +115,12 @@ func (r *Response) reset(w http.ResponseWriter, req *http.Request, h engine.Head
 	r.writer = w
 	r.body = nil
 	r.keepBody = false
+	r.hijacked = false
 	r.responseWriter = &responseWriter{r}
 }
 
 func (r *Response) Hijacker(fn func(net.Conn)) error {
-	conn, bufrw, err := r.ResponseWriter.(http.Hijacker).Hijack()
+	conn, bufrw, err := r.Hijack()
 	if err != nil {
 		return err
 	}
@@ -174,8 +178,26 @@ func (r *Response) Flush() {
 	}
 }
 
+// Push initiates an HTTP/2 server push, it returns `http.ErrNotSupported`
+// if the underlying `http.ResponseWriter` does not support it.
+func (r *Response) Push(target string, opts *http.PushOptions) error {
+	if pusher, ok := r.ResponseWriter.(http.Pusher); ok {
+		return pusher.Push(target, opts)
+	}
+	return http.ErrNotSupported
+}
+
 func (r *Response) Hijack() (net.Conn, *bufio.ReadWriter, error) {
-	return r.ResponseWriter.(http.Hijacker).Hijack()
+	conn, bufrw, err := r.ResponseWriter.(http.Hijacker).Hijack()
+	if err == nil {
+		r.hijacked = true
+	}
+	return conn, bufrw, err
+}
+
+// Hijacked reports whether the connection has been hijacked.
+func (r *Response) Hijacked() bool {
+	return r.hijacked
 }
 
 func (r *Response) CloseNotify() <-chan bool {
@@ -46,9 +46,11 @@ func NewWithTLS(addr, certFile, keyFile string) *Server {
 func NewWithConfig(c *engine.Config) (s *Server) {
 	s = &Server{
 		Server: &http.Server{
-			ReadTimeout:  c.ReadTimeout,
-			WriteTimeout: c.WriteTimeout,
-			Addr:         c.Address,
+			ReadTimeout:    c.ReadTimeout,
+			WriteTimeout:   c.WriteTimeout,
+			IdleTimeout:    c.IdleTimeout,
+			Addr:           c.Address,
+			MaxHeaderBytes: c.MaxRequestHeaderSize,
 		},
 		config: c,
 		pool: &pool{
@@ -95,6 +97,11 @@ func (s *Server) SetLogger(l logger.Logger) {
 	s.logger = l
 }
 
+// Config returns the server config.
+func (s *Server) Config() *engine.Config {
+	return s.config
+}
+
 // Start implements `engine.Server#Start` function.
 func (s *Server) Start() error {
 	if s.config.Listener == nil {
@@ -121,6 +128,11 @@ func (s *Server) Shutdown(ctx context.Context) error {
 
 // ServeHTTP implements `http.Handler` interface.
 func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if code := s.config.CheckRequestLimits(len(r.RequestURI), headerCount(r.Header)); code != 0 {
+		http.Error(w, http.StatusText(code), code)
+		return
+	}
+
 	// Request
 	req := s.pool.request.Get().(*Request)
 	reqHdr := s.pool.requestHeader.Get().(*Header)
@@ -138,6 +150,10 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	res.config = s.config
 
 	s.handler.ServeHTTP(req, res)
+	if res.hijacked {
+		// the hijacked connection may still use them
+		return
+	}
 
 	s.pool.request.Put(req)
 	s.pool.requestHeader.Put(reqHdr)
@@ -145,3 +161,10 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	s.pool.response.Put(res)
 	s.pool.responseHeader.Put(resHdr)
 }
+
+func headerCount(h http.Header) (count int) {
+	for _, values := range h {
+		count += len(values)
+	}
+	return
+}
@@ -0,0 +1,208 @@
+package standard
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/webx-top/echo"
+	"github.com/webx-top/echo/engine"
+)
+
+func TestServerRequestLimits(t *testing.T) {
+	s := NewWithConfig(&engine.Config{
+		MaxRequestURILength:  32,
+		MaxRequestHeaderSize: 256,
+	})
+	s.SetHandler(engine.HandlerFunc(func(req engine.Request, res engine.Response) {
+		res.Write([]byte(`OK`))
+	}))
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ok", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `OK`, rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/"+strings.Repeat("a", 64), nil))
+	assert.Equal(t, http.StatusRequestURITooLong, rec.Code)
+
+	// the header size is limited by net/http, which allows 4KB more
+	assert.Equal(t, 256, s.Server.MaxHeaderBytes)
+	ln, err := net.Listen(`tcp`, `127.0.0.1:0`)
+	if !assert.NoError(t, err) {
+		return
+	}
+	s.config.Listener = ln
+	go s.Start()
+	defer s.Stop()
+
+	get := func(header string) int {
+		req, _ := http.NewRequest(http.MethodGet, `http://`+ln.Addr().String()+`/ok`, nil)
+		req.Header.Set(`X-Large`, header)
+		resp, err := http.DefaultClient.Do(req)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	assert.Equal(t, http.StatusOK, get(strings.Repeat("b", 512)))
+	assert.Equal(t, http.StatusRequestHeaderFieldsTooLarge, get(strings.Repeat("b", 8192)))
+}
+
+func TestServerMaxRequestHeaders(t *testing.T) {
+	s := NewWithConfig(&engine.Config{MaxRequestHeaders: 3})
+	s.SetHandler(engine.HandlerFunc(func(req engine.Request, res engine.Response) {
+		res.Write([]byte(`OK`))
+	}))
+
+	request := func(n int) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		for i := 0; i < n; i++ {
+			req.Header.Add(`X-Test`, strconv.Itoa(i))
+		}
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, req)
+		return rec
+	}
+	assert.Equal(t, http.StatusOK, request(3).Code)
+	assert.Equal(t, http.StatusRequestHeaderFieldsTooLarge, request(4).Code)
+}
+
+func TestHeaderCount(t *testing.T) {
+	h := http.Header{}
+	h.Add(`X-Test`, `1`)
+	h.Add(`X-Test`, `2`)
+	h.Set(`Accept`, `*/*`)
+	assert.Equal(t, 3, engine.HeaderCount(&Header{Header: h}))
+}
+
+func TestRequestRealIP(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = `[2001:db8::1%eth0]:443`
+	assert.Equal(t, `2001:db8::1`, NewRequest(req).RealIP())
+
+	req.RemoteAddr = `192.0.2.1:1234`
+	assert.Equal(t, `192.0.2.1`, NewRequest(req).RealIP())
+
+	req.Header.Set(echo.HeaderXForwardedFor, `[2001:db8::2%eth1]:8443, 192.0.2.2`)
+	assert.Equal(t, `2001:db8::2`, NewRequest(req).RealIP())
+}
+
+func TestServerIdleTimeout(t *testing.T) {
+	ln, err := net.Listen(`tcp`, `127.0.0.1:0`)
+	if !assert.NoError(t, err) {
+		return
+	}
+	s := NewWithConfig(&engine.Config{
+		Address:     ln.Addr().String(),
+		Listener:    ln,
+		ReadTimeout: time.Second,
+		IdleTimeout: 200 * time.Millisecond,
+	})
+	// the idle timeout does not replace the read deadline of a request
+	assert.Equal(t, time.Second, s.Server.ReadTimeout)
+	s.SetHandler(engine.HandlerFunc(func(req engine.Request, res engine.Response) {
+		res.Write([]byte(`OK`))
+	}))
+	go s.Start()
+	defer s.Stop()
+
+	dial := func() (net.Conn, *bufio.Reader) {
+		conn, err := net.Dial(`tcp`, ln.Addr().String())
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		return conn, bufio.NewReader(conn)
+	}
+	get := func(conn net.Conn, r *bufio.Reader) error {
+		if _, err := conn.Write([]byte("GET / HTTP/1.1\r\nHost: localhost\r\n\r\n")); err != nil {
+			return err
+		}
+		resp, err := http.ReadResponse(r, nil)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		_, err = ioutil.ReadAll(resp.Body)
+		return err
+	}
+
+	// an active connection stays open beyond the idle timeout
+	active, activeReader := dial()
+	defer active.Close()
+	for i := 0; i < 6; i++ {
+		if !assert.NoError(t, get(active, activeReader), `request %d`, i) {
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	// an idle connection is closed by the server after the idle timeout
+	idle, idleReader := dial()
+	defer idle.Close()
+	if !assert.NoError(t, get(idle, idleReader)) {
+		return
+	}
+	start := time.Now()
+	idle.SetReadDeadline(start.Add(5 * time.Second))
+	_, err = idleReader.ReadByte()
+	assert.Equal(t, io.EOF, err)
+	assert.True(t, time.Since(start) < 5*time.Second)
+}
+
+func TestServerMaxRequestBodySize(t *testing.T) {
+	ln, err := net.Listen(`tcp`, `127.0.0.1:0`)
+	if !assert.NoError(t, err) {
+		return
+	}
+	s := NewWithConfig(&engine.Config{Address: ln.Addr().String(), Listener: ln, MaxRequestBodySize: 64})
+	e := echo.New()
+	e.Post("/", func(c echo.Context) error {
+		b, err := ioutil.ReadAll(c.RequestBody())
+		if err != nil {
+			return err
+		}
+		return c.String(string(b))
+	})
+	go e.Run(s)
+	defer e.Shutdown(context.Background())
+
+	post := func(raw string) *http.Response {
+		buf := new(bytes.Buffer)
+		gw := gzip.NewWriter(buf)
+		gw.Write([]byte(raw))
+		gw.Close()
+		req, _ := http.NewRequest(http.MethodPost, `http://`+ln.Addr().String()+`/`, buf)
+		req.Header.Set(echo.HeaderContentEncoding, `gzip`)
+		resp, err := http.DefaultClient.Do(req)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		return resp
+	}
+
+	resp := post(`hello`)
+	b, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, `hello`, string(b))
+
+	// the limit applies to the decompressed size
+	resp = post(strings.Repeat(`a`, 1024))
+	resp.Body.Close()
+	assert.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+}
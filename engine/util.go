@@ -1,6 +1,7 @@
 package engine
 
 import (
+	"net"
 	"strconv"
 	"strings"
 	"unsafe"
@@ -32,3 +33,19 @@ func AddressPort(address string) int {
 	}
 	return 80
 }
+
+// AddressIP returns the IP of the address, without the port, the brackets of
+// an IPv6 address and its zone, e.g. `[2001:db8::1%eth0]:443` gives
+// `2001:db8::1` and `192.0.2.1:80` gives `192.0.2.1`.
+func AddressIP(address string) string {
+	address = strings.TrimSpace(address)
+	if host, _, err := net.SplitHostPort(address); err == nil {
+		address = host
+	} else if len(address) > 1 && address[0] == '[' && address[len(address)-1] == ']' {
+		address = address[1 : len(address)-1]
+	}
+	if i := strings.IndexByte(address, '%'); i >= 0 {
+		address = address[:i]
+	}
+	return address
+}
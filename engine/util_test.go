@@ -0,0 +1,16 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddressIP(t *testing.T) {
+	assert.Equal(t, `2001:db8::1`, AddressIP(`[2001:db8::1%eth0]:443`))
+	assert.Equal(t, `2001:db8::1`, AddressIP(`[2001:db8::1]:443`))
+	assert.Equal(t, `2001:db8::1`, AddressIP(`[2001:db8::1]`))
+	assert.Equal(t, `fe80::1`, AddressIP(`fe80::1%eth0`))
+	assert.Equal(t, `192.0.2.1`, AddressIP(`192.0.2.1:8080`))
+	assert.Equal(t, `192.0.2.1`, AddressIP(` 192.0.2.1 `))
+}
@@ -28,6 +28,7 @@ import (
 	"runtime"
 	"strconv"
 	"strings"
+	"syscall"
 
 	pkgCode "github.com/webx-top/echo/code"
 )
@@ -105,6 +106,19 @@ func (e *Error) Unwrap() error { return e.cause }
 // HTTPError
 // ==========================================
 
+// IsClientDisconnect reports whether the error is caused by the client closing
+// the connection while the response is being written (broken pipe / connection reset).
+func IsClientDisconnect(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, syscall.EPIPE) || errors.Is(err, syscall.ECONNRESET) {
+		return true
+	}
+	msg := err.Error()
+	return strings.Contains(msg, `broken pipe`) || strings.Contains(msg, `connection reset`)
+}
+
 func NewHTTPError(code int, msg ...string) *HTTPError {
 	he := &HTTPError{Code: code, Message: http.StatusText(code)}
 	if len(msg) > 0 {
@@ -123,6 +137,15 @@ func (e *HTTPError) Error() string {
 	return e.Message
 }
 
+// ProblemDetails is the RFC 7807 `application/problem+json` error body.
+type ProblemDetails struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+}
+
 // ==========================================
 // PanicError
 // ==========================================
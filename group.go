@@ -1,5 +1,7 @@
 package echo
 
+import "strings"
+
 type Group struct {
 	host       *host
 	prefix     string
@@ -51,7 +53,13 @@ func (g *Group) Use(middleware ...interface{}) {
 	}
 }
 
-// Pre adds handler to the middleware chain.
+// PreUse adds handler to the beginning of the group middleware chain, it runs
+// before the middleware previously added by `Use`. It is an alias for `Pre`.
+func (g *Group) PreUse(middleware ...interface{}) {
+	g.Pre(middleware...)
+}
+
+// Pre adds handler to the beginning of the middleware chain.
 func (g *Group) Pre(middleware ...interface{}) {
 	var middlewares []interface{}
 	for _, m := range middleware {
@@ -100,6 +108,7 @@ func (g *Group) Trace(path string, h interface{}, m ...interface{}) IRouter {
 	return g.Add(TRACE, path, h, m...)
 }
 
+// Any registers a route for all the HTTP methods, with the group prefix and middleware.
 func (g *Group) Any(path string, h interface{}, middleware ...interface{}) IRouter {
 	routes := Routes{}
 	for _, m := range methods {
@@ -108,10 +117,12 @@ func (g *Group) Any(path string, h interface{}, middleware ...interface{}) IRout
 	return routes
 }
 
+// Route registers a route for the HTTP methods separated by commas or spaces, e.g. `GET,POST`.
 func (g *Group) Route(methods string, path string, h interface{}, middleware ...interface{}) IRouter {
-	return g.Match(splitHTTPMethod.Split(methods, -1), path, h, middleware...)
+	return g.Match(splitHTTPMethod.Split(strings.ToUpper(methods), -1), path, h, middleware...)
 }
 
+// Match registers a route for the HTTP methods provided.
 func (g *Group) Match(methods []string, path string, h interface{}, middleware ...interface{}) IRouter {
 	routes := Routes{}
 	for _, m := range methods {
@@ -135,7 +146,61 @@ func (g *Group) Group(prefix string, middleware ...interface{}) *Group {
 		}
 		return subG
 	}
-	return g.echo.Group(g.prefix+prefix, m...)
+	// the defaults of `Echo.SetGroupDefaults` are already inherited from g
+	return g.echo.group(g.prefix+prefix, nil, m...)
+}
+
+// Mount moves the group `sub` (with its routes and middleware) under `prefix`
+// of this group, e.g. `e.Host("admin.example.com").Mount("", admin)`. The
+// middleware of this group runs before the middleware of `sub`, and routes
+// added to `sub` afterwards are registered under the mount point as well.
+// Call `Echo.RebuildRouter` when mounting after the router has been built.
+func (g *Group) Mount(prefix string, sub *Group) *Group {
+	var hostName, subHostName string
+	if g.host != nil {
+		hostName = g.host.name
+	}
+	if sub.host != nil {
+		subHostName = sub.host.name
+	}
+	oldPrefix := sub.prefix
+	newPrefix := g.prefix + prefix
+	for _, r := range g.echo.router.routes {
+		if r.Host != subHostName {
+			continue
+		}
+		if r.Prefix != oldPrefix && !strings.HasPrefix(r.Prefix, oldPrefix+`/`) {
+			continue
+		}
+		m := []interface{}{}
+		m = append(m, g.middleware...)
+		m = append(m, r.middleware...)
+		r.Host = hostName
+		r.Path = g.echo.prefix + newPrefix + strings.TrimPrefix(r.Path, g.echo.prefix+oldPrefix)
+		r.Prefix = newPrefix + strings.TrimPrefix(r.Prefix, oldPrefix)
+		r.middleware = m
+	}
+	if sub.host == nil {
+		if v, y := g.echo.groups[oldPrefix]; y && v == sub {
+			delete(g.echo.groups, oldPrefix)
+		}
+	} else if hs, y := g.echo.hosts[subHostName]; y {
+		if v, y := hs.groups[oldPrefix]; y && v == sub {
+			delete(hs.groups, oldPrefix)
+		}
+	}
+	m := []interface{}{}
+	m = append(m, g.middleware...)
+	m = append(m, sub.middleware...)
+	sub.middleware = m
+	sub.host = g.host
+	sub.prefix = newPrefix
+	if g.host == nil {
+		g.echo.groups[newPrefix] = sub
+	} else {
+		g.echo.hosts[hostName].groups[newPrefix] = sub
+	}
+	return sub
 }
 
 // Static implements `Echo#Static()` for sub-routes within the Group.
@@ -1,19 +1,24 @@
 package echo
 
 import (
+	"bytes"
+	stdjson "encoding/json"
 	"fmt"
 	"mime"
+	"net/http"
 	"net/url"
 	"os"
 	"path"
 	"path/filepath"
 	"reflect"
 	"runtime"
+	"strconv"
 	"strings"
 
 	"github.com/admpub/log"
 	"github.com/webx-top/com"
 	"github.com/webx-top/echo/encoding/json"
+	"github.com/webx-top/echo/engine"
 )
 
 var workDir string
@@ -119,6 +124,182 @@ func ContentTypeByExtension(name string) (t string) {
 	return
 }
 
+// AddVary appends the header names to the `Vary` header as a single
+// comma-joined value without duplicates. Only the first existing `Vary` value
+// is merged, so the header should always be extended with `AddVary`.
+func AddVary(header engine.Header, names ...string) {
+	var vary []string
+	seen := map[string]struct{}{}
+	add := func(name string) {
+		name = strings.TrimSpace(name)
+		if len(name) == 0 {
+			return
+		}
+		key := strings.ToLower(name)
+		if _, ok := seen[key]; ok {
+			return
+		}
+		seen[key] = struct{}{}
+		vary = append(vary, name)
+	}
+	for _, name := range strings.Split(header.Get(HeaderVary), `,`) {
+		add(name)
+	}
+	for _, name := range names {
+		add(name)
+	}
+	if len(vary) > 0 {
+		header.Set(HeaderVary, strings.Join(vary, `, `))
+	}
+}
+
+// cacheControlConflicts lists the directives removed by the directive.
+var cacheControlConflicts = map[string][]string{
+	`no-store`: {`max-age`, `s-maxage`},
+	`public`:   {`private`},
+	`private`:  {`public`},
+}
+
+// MergeCacheControl merges the directives into the `Cache-Control` value
+// current. A directive replaces the one of the same name, e.g. `max-age=60`
+// replaces `max-age=0`, `public` and `private` replace each other and
+// `no-store` removes `max-age` and `s-maxage`, which are ignored after it.
+func MergeCacheControl(current string, directives ...string) string {
+	var (
+		names  []string
+		values = map[string]string{}
+	)
+	add := func(directive string) {
+		directive = strings.TrimSpace(directive)
+		if len(directive) == 0 {
+			return
+		}
+		parts := strings.SplitN(directive, `=`, 2)
+		name := strings.ToLower(strings.TrimSpace(parts[0]))
+		if len(parts) == 2 {
+			directive = name + `=` + strings.TrimSpace(parts[1])
+		} else {
+			directive = name
+		}
+		if _, ok := values[`no-store`]; ok && (name == `max-age` || name == `s-maxage`) {
+			return
+		}
+		for _, conflict := range cacheControlConflicts[name] {
+			if _, ok := values[conflict]; ok {
+				delete(values, conflict)
+				for i, v := range names {
+					if v == conflict {
+						names = append(names[:i], names[i+1:]...)
+						break
+					}
+				}
+			}
+		}
+		if _, ok := values[name]; !ok {
+			names = append(names, name)
+		}
+		values[name] = directive
+	}
+	for _, directive := range splitCacheControl(current) {
+		add(directive)
+	}
+	for _, directive := range directives {
+		add(directive)
+	}
+	result := make([]string, len(names))
+	for i, name := range names {
+		result[i] = values[name]
+	}
+	return strings.Join(result, `, `)
+}
+
+// splitCacheControl splits the `Cache-Control` value on the commas outside of
+// the quoted strings, e.g. `private="Set-Cookie, X-Token", max-age=0`.
+func splitCacheControl(value string) []string {
+	var (
+		directives []string
+		quoted     bool
+		start      int
+	)
+	for i := 0; i < len(value); i++ {
+		switch value[i] {
+		case '\\':
+			if quoted {
+				i++
+			}
+		case '"':
+			quoted = !quoted
+		case ',':
+			if !quoted {
+				directives = append(directives, value[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(directives, value[start:])
+}
+
+// Precompressed describes the file extension of a precompressed variant of
+// the static files and its `Content-Encoding`.
+type Precompressed struct {
+	Encoding  string
+	Extension string
+}
+
+// PrecompressedEncodings are the precompressed variants `Context.File` looks
+// for, in order of preference.
+var PrecompressedEncodings = []Precompressed{
+	{Encoding: `br`, Extension: `.br`},
+	{Encoding: `gzip`, Extension: `.gz`},
+}
+
+// NegotiateEncoding picks the best encoding from `Accept-Encoding` among
+// the supported ones by quality value. Ties are broken by the order of supported.
+func NegotiateEncoding(acceptEncoding string, supported []string) string {
+	qualities := map[string]float64{}
+	wildcard := -1.0
+	for _, part := range strings.Split(acceptEncoding, `,`) {
+		part = strings.TrimSpace(part)
+		if len(part) == 0 {
+			continue
+		}
+		name := part
+		quality := 1.0
+		if pos := strings.Index(part, `;`); pos > -1 {
+			name = strings.TrimSpace(part[:pos])
+			param := strings.TrimSpace(part[pos+1:])
+			if strings.HasPrefix(param, `q=`) {
+				q, err := strconv.ParseFloat(param[2:], 64)
+				if err != nil {
+					continue
+				}
+				quality = q
+			}
+		}
+		name = strings.ToLower(name)
+		if name == `*` {
+			wildcard = quality
+			continue
+		}
+		qualities[name] = quality
+	}
+	var (
+		best        string
+		bestQuality float64
+	)
+	for _, name := range supported {
+		quality, ok := qualities[name]
+		if !ok {
+			quality = wildcard
+		}
+		if quality > bestQuality {
+			best = name
+			bestQuality = quality
+		}
+	}
+	return best
+}
+
 func static(r RouteRegister, prefix, root string) {
 	var err error
 	root, err = filepath.Abs(root)
@@ -127,16 +308,25 @@ func static(r RouteRegister, prefix, root string) {
 	}
 	h := func(c Context) error {
 		name := filepath.Join(root, c.Param("*"))
-		if !strings.HasPrefix(name, root) {
+		if name != root && !strings.HasPrefix(name, root+string(filepath.Separator)) {
 			return ErrNotFound
 		}
 		return c.File(name)
 	}
-	if prefix == "/" {
-		r.Get(prefix+"*", h)
-	} else {
-		r.Get(prefix+"/*", h)
+	prefix = strings.TrimRight(prefix, "/")
+	r.Get(prefix+"/*", h)
+	if len(prefix) == 0 {
+		return
 	}
+	// `/static` redirects to `/static/`, so relative links in the index resolve.
+	r.Get(prefix, func(c Context) error {
+		u := c.Request().URL()
+		target := u.Path() + "/"
+		if q := u.RawQuery(); len(q) > 0 {
+			target += "?" + q
+		}
+		return c.Redirect(target, http.StatusMovedPermanently)
+	})
 }
 
 func Clear(old []interface{}, clears ...interface{}) []interface{} {
@@ -219,3 +409,79 @@ func URLDecode(encoded string, rfc ...bool) (string, error) {
 	}
 	return url.QueryUnescape(encoded)
 }
+
+// OmitEmptyJSON removes the object members whose value is null, an empty
+// string, an empty array or an object left empty, recursively. Array elements
+// are kept to preserve their positions, zero numbers and false are kept too.
+func OmitEmptyJSON(b []byte) ([]byte, error) {
+	dec := stdjson.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	buf := new(bytes.Buffer)
+	if _, err := writeOmitEmptyJSON(dec, buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeOmitEmptyJSON copies the next value of dec to buf and reports whether
+// it is empty.
+func writeOmitEmptyJSON(dec *stdjson.Decoder, buf *bytes.Buffer) (bool, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return false, err
+	}
+	switch v := tok.(type) {
+	case stdjson.Delim:
+		isObject := v == '{'
+		buf.WriteByte(byte(v))
+		var n int
+		for dec.More() {
+			mark := buf.Len()
+			if n > 0 {
+				buf.WriteByte(',')
+			}
+			if isObject {
+				key, err := dec.Token()
+				if err != nil {
+					return false, err
+				}
+				b, err := stdjson.Marshal(key)
+				if err != nil {
+					return false, err
+				}
+				buf.Write(b)
+				buf.WriteByte(':')
+			}
+			empty, err := writeOmitEmptyJSON(dec, buf)
+			if err != nil {
+				return false, err
+			}
+			if empty && isObject {
+				buf.Truncate(mark)
+				continue
+			}
+			n++
+		}
+		end, err := dec.Token()
+		if err != nil {
+			return false, err
+		}
+		buf.WriteByte(byte(end.(stdjson.Delim)))
+		return n == 0, nil
+	case string:
+		b, err := stdjson.Marshal(v)
+		if err != nil {
+			return false, err
+		}
+		buf.Write(b)
+		return len(v) == 0, nil
+	case stdjson.Number:
+		buf.WriteString(v.String())
+	case bool:
+		buf.WriteString(strconv.FormatBool(v))
+	case nil:
+		buf.WriteString(`null`)
+		return true, nil
+	}
+	return false, nil
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/webx-top/echo"
+	test "github.com/webx-top/echo/testing"
 )
 
 func testHandlerFunc(ctx echo.Context) error {
@@ -45,3 +46,44 @@ func TestURLEncode(t *testing.T) {
 	content, _ = echo.URLDecode(encoded, true)
 	assert.Equal(t, raw, content)
 }
+
+func TestMergeCacheControl(t *testing.T) {
+	assert.Equal(t, `public, max-age=60`, echo.MergeCacheControl(``, `public`, `max-age=60`))
+	assert.Equal(t, `max-age=60, private`, echo.MergeCacheControl(`public, max-age=0`, `max-age=60`, `private`))
+	assert.Equal(t, `private, no-store`, echo.MergeCacheControl(`max-age=60, private`, `no-store`))
+	assert.Equal(t, `no-store`, echo.MergeCacheControl(`no-store`, `max-age=60`, `NO-STORE`))
+	assert.Equal(t, `no-cache="Set-Cookie, X-Token", max-age=60`, echo.MergeCacheControl(`no-cache="Set-Cookie, X-Token", max-age=0`, `max-age=60`))
+	assert.Equal(t, `no-cache="a\", b", private`, echo.MergeCacheControl(`no-cache="a\", b"`, `private`))
+}
+
+func TestNegotiateEncoding(t *testing.T) {
+	supported := []string{`br`, `gzip`}
+	assert.Equal(t, `br`, echo.NegotiateEncoding(`gzip, deflate, br`, supported))
+	assert.Equal(t, `gzip`, echo.NegotiateEncoding(`GZIP;q=0.5`, supported))
+	assert.Equal(t, `br`, echo.NegotiateEncoding(`*`, supported))
+	assert.Equal(t, `gzip`, echo.NegotiateEncoding(`gzip, br;q=0`, supported))
+	assert.Equal(t, ``, echo.NegotiateEncoding(`deflate, *;q=0`, supported))
+	assert.Equal(t, ``, echo.NegotiateEncoding(``, supported))
+}
+
+func TestOmitEmptyJSON(t *testing.T) {
+	b, err := echo.OmitEmptyJSON([]byte(`{"b":null,"a":"x","c":"","d":[],"e":{"f":null,"g":{}},"h":[null,"",0,{"i":null}],"j":false,"k":0}`))
+	assert.NoError(t, err)
+	assert.Equal(t, `{"a":"x","h":[null,"",0,{}],"j":false,"k":0}`, string(b))
+
+	b, err = echo.OmitEmptyJSON([]byte(`{"a":null}`))
+	assert.NoError(t, err)
+	assert.Equal(t, `{}`, string(b))
+
+	_, err = echo.OmitEmptyJSON([]byte(`{"a":`))
+	assert.Error(t, err)
+}
+
+func TestAddVary(t *testing.T) {
+	_, res := test.NewRequestAndResponse(echo.GET, "/")
+	header := res.Header()
+	header.Set(echo.HeaderVary, `Cookie, origin`)
+	echo.AddVary(header, echo.HeaderAccept)
+	echo.AddVary(header, echo.HeaderOrigin, echo.HeaderAcceptEncoding, echo.HeaderAcceptEncoding)
+	assert.Equal(t, `Cookie, origin, Accept, Accept-Encoding`, header.Get(echo.HeaderVary))
+}
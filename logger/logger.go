@@ -25,6 +25,12 @@ type (
 		SetLevel(string)
 	}
 
+	// FieldsLogger is implemented by the loggers which support structured
+	// fields, the returned Logger writes the fields with every entry.
+	FieldsLogger interface {
+		WithFields(map[string]interface{}) Logger
+	}
+
 	Base struct {
 	}
 )
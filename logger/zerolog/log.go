@@ -15,7 +15,8 @@ var (
 	// Default default global logger
 	Default = New()
 
-	_ logger.Logger = Default
+	_ logger.Logger       = Default
+	_ logger.FieldsLogger = Default
 )
 
 func init() {
@@ -108,6 +109,17 @@ func (a *Logger) GetLogger(category string, writers ...io.Writer) *Logger {
 	return subLogger
 }
 
+// WithFields returns a copy of the logger which writes the fields with every entry.
+func (a *Logger) WithFields(fields map[string]interface{}) logger.Logger {
+	l := a.Logger.With().Fields(fields).Logger()
+	return &Logger{
+		Logger: &l,
+		Base:   a.Base,
+		mutex:  a.mutex,
+		subs:   make(map[string]*Logger),
+	}
+}
+
 func (a *Logger) SetLevel(level string) {
 	level = strings.ToLower(level)
 	lv, err := zerolog.ParseLevel(level)
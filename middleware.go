@@ -28,8 +28,29 @@ type (
 	// Skipper defines a function to skip middleware. Returning true skips processing
 	// the middleware.
 	Skipper func(c Context) bool
+
+	namedMiddleware struct {
+		name string
+		Middleware
+	}
 )
 
+func middlewareName(m interface{}) string {
+	if nm, ok := m.(*namedMiddleware); ok {
+		return nm.name
+	}
+	return HandlerName(m)
+}
+
+func (m *namedMiddleware) named(names []string) bool {
+	for _, name := range names {
+		if m.name == name {
+			return true
+		}
+	}
+	return false
+}
+
 func CaptureTokens(pattern *regexp.Regexp, input string) *strings.Replacer {
 	groups := pattern.FindAllStringSubmatch(input, -1)
 	if groups == nil {
@@ -49,3 +70,41 @@ func CaptureTokens(pattern *regexp.Regexp, input string) *strings.Replacer {
 func DefaultSkipper(c Context) bool {
 	return false
 }
+
+// MethodFilter wraps the middleware so that it only runs for requests using
+// one of the methods, passing through otherwise.
+func MethodFilter(mw interface{}, methods ...string) Middleware {
+	m := WrapMiddleware(mw)
+	allowed := make(map[string]struct{}, len(methods))
+	for _, method := range methods {
+		allowed[strings.ToUpper(method)] = struct{}{}
+	}
+	return MiddlewareFunc(func(next Handler) Handler {
+		h := m.Handle(next)
+		return HandlerFunc(func(c Context) error {
+			if _, ok := allowed[c.Request().Method()]; ok {
+				return h.Handle(c)
+			}
+			return next.Handle(c)
+		})
+	})
+}
+
+// Skip wraps the middleware so that it is bypassed for the requests on which
+// the skipper returns true, e.g. to apply a middleware without a `Skipper`
+// config to all paths but some.
+func Skip(mw interface{}, skipper Skipper) Middleware {
+	m := WrapMiddleware(mw)
+	if skipper == nil {
+		return m
+	}
+	return MiddlewareFunc(func(next Handler) Handler {
+		h := m.Handle(next)
+		return HandlerFunc(func(c Context) error {
+			if skipper(c) {
+				return next.Handle(c)
+			}
+			return h.Handle(c)
+		})
+	})
+}
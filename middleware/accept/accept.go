@@ -0,0 +1,85 @@
+package accept
+
+import (
+	"strings"
+
+	"github.com/webx-top/echo"
+)
+
+// ContentTypeConfig defines the config for ContentType middleware.
+type ContentTypeConfig struct {
+	// Skipper defines a function to skip middleware.
+	Skipper echo.Skipper `json:"-"`
+
+	// Types lists the allowed request content types, e.g. `application/json`.
+	// `type/*` allows every subtype of a type.
+	Types []string `json:"types"`
+
+	// Methods lists the request methods whose body is checked.
+	// Default is POST, PUT and PATCH.
+	Methods []string `json:"methods"`
+}
+
+// DefaultContentTypeConfig is the default ContentType middleware config.
+var DefaultContentTypeConfig = ContentTypeConfig{
+	Skipper: echo.DefaultSkipper,
+	Methods: []string{echo.POST, echo.PUT, echo.PATCH},
+}
+
+// EnforceContentType returns a middleware which rejects the requests with a body
+// whose `Content-Type` is not one of the types with "415 - Unsupported Media Type".
+func EnforceContentType(types ...string) echo.MiddlewareFuncd {
+	config := DefaultContentTypeConfig
+	config.Types = types
+	return EnforceContentTypeWithConfig(config)
+}
+
+// EnforceContentTypeWithConfig returns a ContentType middleware from config.
+// See: `EnforceContentType()`.
+func EnforceContentTypeWithConfig(config ContentTypeConfig) echo.MiddlewareFuncd {
+	if config.Skipper == nil {
+		config.Skipper = DefaultContentTypeConfig.Skipper
+	}
+	if len(config.Methods) == 0 {
+		config.Methods = DefaultContentTypeConfig.Methods
+	}
+	methods := make(map[string]struct{}, len(config.Methods))
+	for _, method := range config.Methods {
+		methods[strings.ToUpper(method)] = struct{}{}
+	}
+	types := make([]string, len(config.Types))
+	for i, typ := range config.Types {
+		types[i] = strings.ToLower(strings.TrimSpace(typ))
+	}
+	return func(next echo.Handler) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.Skipper(c) {
+				return next.Handle(c)
+			}
+			if _, ok := methods[c.Method()]; !ok {
+				return next.Handle(c)
+			}
+			contentType := c.Header(echo.HeaderContentType)
+			if len(contentType) == 0 && c.Request().Size() == 0 {
+				// no body
+				return next.Handle(c)
+			}
+			if !allowed(types, c.ResolveContentType()) {
+				return echo.ErrUnsupportedMediaType
+			}
+			return next.Handle(c)
+		}
+	}
+}
+
+func allowed(types []string, contentType string) bool {
+	for _, typ := range types {
+		if typ == contentType {
+			return true
+		}
+		if strings.HasSuffix(typ, `/*`) && strings.HasPrefix(contentType, typ[:len(typ)-1]) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,43 @@
+package accept
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/webx-top/echo"
+	test "github.com/webx-top/echo/testing"
+)
+
+func TestEnforceContentType(t *testing.T) {
+	e := echo.New()
+	e.Use(EnforceContentType(echo.MIMEApplicationJSON, `image/*`))
+	handler := func(c echo.Context) error {
+		return c.String(`OK`)
+	}
+	e.Post("/", handler)
+	e.Get("/", handler)
+	e.RebuildRouter()
+
+	request := func(method string, contentType string, body string) int {
+		return test.Request(method, "/", e, func(req *http.Request) {
+			if len(body) > 0 {
+				r, _ := http.NewRequest(method, "/", strings.NewReader(body))
+				req.Body = r.Body
+				req.ContentLength = int64(len(body))
+			}
+			if len(contentType) > 0 {
+				req.Header.Set(echo.HeaderContentType, contentType)
+			}
+		}).Code
+	}
+
+	assert.Equal(t, http.StatusOK, request(echo.POST, echo.MIMEApplicationJSONCharsetUTF8, `{}`))
+	assert.Equal(t, http.StatusOK, request(echo.POST, `image/png`, `png`))
+	assert.Equal(t, http.StatusUnsupportedMediaType, request(echo.POST, echo.MIMETextPlain, `text`))
+	assert.Equal(t, http.StatusUnsupportedMediaType, request(echo.POST, ``, `text`))
+	assert.Equal(t, http.StatusOK, request(echo.POST, ``, ``))
+	assert.Equal(t, http.StatusOK, request(echo.GET, echo.MIMETextPlain, ``))
+}
@@ -3,6 +3,7 @@ package middleware
 import (
 	"fmt"
 	"io"
+	"strings"
 	"sync"
 
 	"github.com/webx-top/echo"
@@ -37,6 +38,9 @@ type (
 // header and actual content read, which makes it super secure.
 // Limit can be specified as `4x` or `4xB`, where x is one of the multiple from K, M,
 // G, T or P.
+//
+// A request sent with `Expect: 100-continue` whose `Content-Length` exceeds the
+// limit is rejected before its body is read, so the client never uploads it.
 func BodyLimit(limit string) echo.MiddlewareFunc {
 	return BodyLimitWithConfig(BodyLimitConfig{Limit: limit})
 }
@@ -65,6 +69,11 @@ func BodyLimitWithConfig(config BodyLimitConfig) echo.MiddlewareFunc {
 
 			// Based on content length
 			if req.Size() > config.limit {
+				if strings.EqualFold(req.Header().Get(echo.HeaderExpect), `100-continue`) {
+					// The body has not been sent yet: answer without reading it
+					// and close the connection instead of waiting for the upload.
+					c.Response().Header().Set(echo.HeaderConnection, `close`)
+				}
 				return echo.ErrStatusRequestEntityTooLarge
 			}
 
@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"bufio"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/webx-top/echo"
+	"github.com/webx-top/echo/engine/standard"
+	test "github.com/webx-top/echo/testing"
+)
+
+func TestBodyLimit(t *testing.T) {
+	e := echo.New()
+	e.Use(BodyLimit(`10B`))
+	e.Post("/", func(c echo.Context) error {
+		b, err := ioutil.ReadAll(c.Request().Body())
+		if err != nil {
+			return err
+		}
+		return c.String(string(b))
+	})
+	e.RebuildRouter()
+
+	rec := test.Request(echo.POST, "/", e, func(req *http.Request) {
+		req.Body = httptest.NewRequest(echo.POST, "/", strings.NewReader(`hello`)).Body
+		req.ContentLength = 5
+	})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `hello`, rec.Body.String())
+
+	rec = test.Request(echo.POST, "/", e, func(req *http.Request) {
+		req.Body = httptest.NewRequest(echo.POST, "/", strings.NewReader(`hello world`)).Body
+		req.ContentLength = 11
+	})
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+}
+
+func TestBodyLimitExpectContinue(t *testing.T) {
+	var called bool
+	e := echo.New()
+	e.Use(BodyLimit(`1KB`))
+	e.Post("/upload", func(c echo.Context) error {
+		called = true
+		return c.String(`OK`)
+	})
+	e.RebuildRouter()
+	s := standard.New(``)
+	s.SetHandler(e)
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	conn, err := net.Dial(`tcp`, srv.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	_, err = conn.Write([]byte("POST /upload HTTP/1.1\r\n" +
+		"Host: example.com\r\n" +
+		"Content-Type: application/octet-stream\r\n" +
+		"Content-Length: 1048576\r\n" +
+		"Expect: 100-continue\r\n\r\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The body is never sent: the server must answer from the headers alone.
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	assert.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+	assert.True(t, resp.Close)
+	assert.False(t, called)
+}
@@ -0,0 +1,72 @@
+package canonicalhost
+
+import (
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/webx-top/echo"
+)
+
+type Config struct {
+	// Skipper defines a function to skip middleware.
+	Skipper echo.Skipper `json:"-"`
+
+	// Host is the canonical host, e.g. `example.com`. When it has no port,
+	// only the host name is compared and the request port is kept.
+	Host string `json:"host"`
+
+	// Code is the status code used when redirecting.
+	// Optional. Default value http.StatusMovedPermanently.
+	Code int `json:"code"`
+}
+
+var (
+	// DefaultConfig is the default CanonicalHost middleware config.
+	DefaultConfig = Config{
+		Skipper: echo.DefaultSkipper,
+		Code:    http.StatusMovedPermanently,
+	}
+)
+
+// CanonicalHost redirects the requests whose host differs from the target to
+// the same path and query on the target host, e.g. `www.example.com` to `example.com`.
+// Usage `Echo#Pre(canonicalhost.CanonicalHost("example.com", http.StatusPermanentRedirect))`
+func CanonicalHost(target string, code int) echo.MiddlewareFuncd {
+	config := DefaultConfig
+	config.Host = target
+	config.Code = code
+	return CanonicalHostWithConfig(config)
+}
+
+// CanonicalHostWithConfig returns a CanonicalHost middleware with config.
+func CanonicalHostWithConfig(config Config) echo.MiddlewareFuncd {
+	// Defaults
+	if config.Skipper == nil {
+		config.Skipper = DefaultConfig.Skipper
+	}
+	if config.Code == 0 {
+		config.Code = DefaultConfig.Code
+	}
+	withPort := strings.Contains(config.Host, `:`)
+	return func(next echo.Handler) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.Skipper(c) || len(config.Host) == 0 {
+				return next.Handle(c)
+			}
+			req := c.Request()
+			host := req.Host()
+			target := config.Host
+			if !withPort {
+				if name, port, err := net.SplitHostPort(host); err == nil {
+					host = name
+					target = net.JoinHostPort(target, port)
+				}
+			}
+			if strings.EqualFold(host, config.Host) {
+				return next.Handle(c)
+			}
+			return c.Redirect(c.Scheme()+`://`+target+req.URI(), config.Code)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package canonicalhost
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/webx-top/echo"
+	test "github.com/webx-top/echo/testing"
+)
+
+func TestCanonicalHost(t *testing.T) {
+	e := echo.New()
+	e.Pre(CanonicalHost(`example.com`, http.StatusPermanentRedirect))
+	e.Get("/users", func(c echo.Context) error {
+		return c.String(`users`)
+	})
+	e.RebuildRouter()
+
+	rec := test.Request(echo.GET, "/users?page=2", e, func(req *http.Request) {
+		req.Host = "www.example.com"
+	})
+	assert.Equal(t, http.StatusPermanentRedirect, rec.Code)
+	assert.Equal(t, "http://example.com/users?page=2", rec.Header().Get(echo.HeaderLocation))
+
+	rec = test.Request(echo.GET, "/users", e, func(req *http.Request) {
+		req.Host = "www.example.com:8080"
+	})
+	assert.Equal(t, http.StatusPermanentRedirect, rec.Code)
+	assert.Equal(t, "http://example.com:8080/users", rec.Header().Get(echo.HeaderLocation))
+
+	for _, host := range []string{"example.com", "EXAMPLE.com", "example.com:8080"} {
+		rec = test.Request(echo.GET, "/users", e, func(req *http.Request) {
+			req.Host = host
+		})
+		assert.Equal(t, http.StatusOK, rec.Code, host)
+		assert.Equal(t, "users", rec.Body.String(), host)
+	}
+}
+
+func TestCanonicalHostWWW(t *testing.T) {
+	e := echo.New()
+	e.Pre(CanonicalHost(`www.example.com`, 0))
+	e.Get("/", func(c echo.Context) error {
+		return c.String(`home`)
+	})
+	e.RebuildRouter()
+
+	rec := test.Request(echo.GET, "/?a=1", e, func(req *http.Request) {
+		req.Host = "example.com"
+	})
+	assert.Equal(t, http.StatusMovedPermanently, rec.Code)
+	assert.Equal(t, "http://www.example.com/?a=1", rec.Header().Get(echo.HeaderLocation))
+}
@@ -3,11 +3,13 @@ package middleware
 import (
 	"bufio"
 	"compress/gzip"
+	"compress/zlib"
 	"io"
 	"io/ioutil"
 	"net"
 	"net/http"
-	"strings"
+	"sort"
+	"sync"
 
 	"github.com/webx-top/echo"
 	"github.com/webx-top/echo/engine"
@@ -24,10 +26,31 @@ type (
 		Level int `json:"level"`
 	}
 
+	// CompressConfig defines the config for Compress middleware.
+	CompressConfig struct {
+		// Skipper defines a function to skip middleware.
+		Skipper echo.Skipper `json:"-"`
+
+		// Compression level passed to the encoder.
+		// Optional. Default value -1.
+		Level int `json:"level"`
+
+		// Encodings limits the negotiable encodings, in order of server preference.
+		// Optional. Default value is all registered encoders.
+		Encodings []string `json:"encodings"`
+	}
+
+	// Encoder creates a compressing writer for a `Content-Encoding`.
+	Encoder func(w io.Writer, level int) (io.WriteCloser, error)
+
 	gzipWriter struct {
 		io.Writer
 		engine.Response
 	}
+
+	switchWriter struct {
+		io.Writer
+	}
 )
 
 var (
@@ -36,14 +59,88 @@ var (
 		Skipper: echo.DefaultSkipper,
 		Level:   -1,
 	}
+
+	// DefaultCompressConfig is the default Compress middleware config.
+	DefaultCompressConfig = &CompressConfig{
+		Skipper: echo.DefaultSkipper,
+		Level:   -1,
+	}
+
+	// EncodingPreference is the server preference when the client accepts
+	// several encodings with the same quality.
+	EncodingPreference = []string{`br`, `zstd`, `gzip`, `deflate`}
+
+	encoders = map[string]Encoder{
+		`gzip`: func(w io.Writer, level int) (io.WriteCloser, error) {
+			return gzip.NewWriterLevel(w, level)
+		},
+		`deflate`: func(w io.Writer, level int) (io.WriteCloser, error) {
+			return zlib.NewWriterLevel(w, level)
+		},
+	}
+	encodersLock sync.RWMutex
 )
 
+// RegisterEncoder registers an encoder for the `Content-Encoding` (e.g. br, zstd).
+func RegisterEncoder(encoding string, encoder Encoder) {
+	encodersLock.Lock()
+	encoders[encoding] = encoder
+	encodersLock.Unlock()
+}
+
+// GetEncoder returns the encoder registered for the `Content-Encoding`.
+func GetEncoder(encoding string) Encoder {
+	encodersLock.RLock()
+	encoder := encoders[encoding]
+	encodersLock.RUnlock()
+	return encoder
+}
+
+// NegotiateEncoding picks the best encoding from `Accept-Encoding` among
+// the supported ones, see `echo.NegotiateEncoding`.
+func NegotiateEncoding(acceptEncoding string, supported []string) string {
+	return echo.NegotiateEncoding(acceptEncoding, supported)
+}
+
+func supportedEncodings(encodings []string) []string {
+	encodersLock.RLock()
+	defer encodersLock.RUnlock()
+	var supported []string
+	if len(encodings) > 0 {
+		for _, name := range encodings {
+			if _, ok := encoders[name]; ok {
+				supported = append(supported, name)
+			}
+		}
+		return supported
+	}
+	rank := func(name string) int {
+		for i, v := range EncodingPreference {
+			if v == name {
+				return i
+			}
+		}
+		return len(EncodingPreference)
+	}
+	for name := range encoders {
+		supported = append(supported, name)
+	}
+	sort.SliceStable(supported, func(i, j int) bool {
+		ri, rj := rank(supported[i]), rank(supported[j])
+		if ri == rj {
+			return supported[i] < supported[j]
+		}
+		return ri < rj
+	})
+	return supported
+}
+
 func (w *gzipWriter) WriteHeader(code int) {
 	if code == http.StatusNoContent {
 		w.Header().Del(echo.HeaderContentEncoding)
 	}
 	w.Header().Del(echo.HeaderContentLength)
-	w.WriteHeader(code)
+	w.Response.WriteHeader(code)
 }
 
 func (w *gzipWriter) Write(b []byte) (int, error) {
@@ -54,7 +151,9 @@ func (w *gzipWriter) Write(b []byte) (int, error) {
 }
 
 func (w *gzipWriter) Flush() {
-	w.Writer.(*gzip.Writer).Flush()
+	if flusher, ok := w.Writer.(interface{ Flush() error }); ok {
+		flusher.Flush()
+	}
 	if flusher, ok := w.Response.(http.Flusher); ok {
 		flusher.Flush()
 		return
@@ -97,7 +196,32 @@ func GzipWithConfig(config *GzipConfig) echo.MiddlewareFunc {
 	if config.Level == 0 {
 		config.Level = DefaultGzipConfig.Level
 	}
-	scheme := `gzip`
+	return CompressWithConfig(&CompressConfig{
+		Skipper:   config.Skipper,
+		Level:     config.Level,
+		Encodings: []string{`gzip`},
+	})
+}
+
+// Compress returns a middleware which compresses HTTP response using the
+// registered encoder that best matches the `Accept-Encoding` request header.
+func Compress(config ...*CompressConfig) echo.MiddlewareFunc {
+	if len(config) < 1 || config[0] == nil {
+		return CompressWithConfig(DefaultCompressConfig)
+	}
+	return CompressWithConfig(config[0])
+}
+
+// CompressWithConfig return Compress middleware with config.
+// See: `Compress()`.
+func CompressWithConfig(config *CompressConfig) echo.MiddlewareFunc {
+	// Defaults
+	if config.Skipper == nil {
+		config.Skipper = DefaultCompressConfig.Skipper
+	}
+	if config.Level == 0 {
+		config.Level = DefaultCompressConfig.Level
+	}
 
 	return func(h echo.Handler) echo.Handler {
 		return echo.HandlerFunc(func(c echo.Context) error {
@@ -105,29 +229,32 @@ func GzipWithConfig(config *GzipConfig) echo.MiddlewareFunc {
 				return h.Handle(c)
 			}
 			resp := c.Response()
-			resp.Header().Add(echo.HeaderVary, echo.HeaderAcceptEncoding)
-			if strings.Contains(c.Request().Header().Get(echo.HeaderAcceptEncoding), scheme) {
-				resp.Header().Add(echo.HeaderContentEncoding, scheme)
-				rw := resp.Writer()
-				w, err := gzip.NewWriterLevel(rw, config.Level)
-				if err != nil {
-					return err
-				}
-				defer func() {
-					if resp.Size() == 0 {
-						if resp.Header().Get(echo.HeaderContentEncoding) == scheme {
-							resp.Header().Del(echo.HeaderContentEncoding)
-						}
-						// We have to reset response to it's pristine state when
-						// nothing is written to body or error is returned.
-						// See issue #424, #407.
-						resp.SetWriter(rw)
-						w.Reset(ioutil.Discard)
-					}
-					w.Close()
-				}()
-				resp.SetWriter(&gzipWriter{Writer: w, Response: resp})
+			c.AddVary(echo.HeaderAcceptEncoding)
+			scheme := NegotiateEncoding(c.Request().Header().Get(echo.HeaderAcceptEncoding), supportedEncodings(config.Encodings))
+			if len(scheme) == 0 {
+				return h.Handle(c)
 			}
+			resp.Header().Add(echo.HeaderContentEncoding, scheme)
+			rw := resp.Writer()
+			sw := &switchWriter{Writer: rw}
+			w, err := GetEncoder(scheme)(sw, config.Level)
+			if err != nil {
+				return err
+			}
+			defer func() {
+				if resp.Size() == 0 {
+					if resp.Header().Get(echo.HeaderContentEncoding) == scheme {
+						resp.Header().Del(echo.HeaderContentEncoding)
+					}
+					// We have to reset response to it's pristine state when
+					// nothing is written to body or error is returned.
+					// See issue #424, #407.
+					resp.SetWriter(rw)
+					sw.Writer = ioutil.Discard
+				}
+				w.Close()
+			}()
+			resp.SetWriter(&gzipWriter{Writer: w, Response: resp})
 			return h.Handle(c)
 		})
 	}
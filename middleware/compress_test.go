@@ -0,0 +1,106 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/webx-top/echo"
+	test "github.com/webx-top/echo/testing"
+)
+
+type testBrotliWriter struct {
+	io.Writer
+}
+
+func (w *testBrotliWriter) Write(b []byte) (int, error) {
+	return w.Writer.Write(append([]byte(`br:`), b...))
+}
+
+func (w *testBrotliWriter) Close() error {
+	return nil
+}
+
+func TestNegotiateEncoding(t *testing.T) {
+	supported := []string{`br`, `gzip`, `deflate`}
+	assert.Equal(t, `br`, NegotiateEncoding(`br, gzip;q=0.8`, supported))
+	assert.Equal(t, `gzip`, NegotiateEncoding(`br;q=0.5, gzip;q=0.8`, supported))
+	assert.Equal(t, `gzip`, NegotiateEncoding(`br;q=0, gzip`, supported))
+	assert.Equal(t, `br`, NegotiateEncoding(`*`, supported))
+	assert.Equal(t, `deflate`, NegotiateEncoding(`identity, deflate`, supported))
+	assert.Equal(t, ``, NegotiateEncoding(`identity`, supported))
+	assert.Equal(t, ``, NegotiateEncoding(``, supported))
+}
+
+func TestCompress(t *testing.T) {
+	e := echo.New()
+	e.Use(Compress())
+	e.Get("/", func(c echo.Context) error {
+		return c.String(`test`)
+	})
+	e.RebuildRouter()
+
+	rec := test.Request(echo.GET, "/", e, func(req *http.Request) {
+		req.Header.Set(echo.HeaderAcceptEncoding, `deflate;q=0.5, gzip;q=0.8`)
+	})
+	assert.Equal(t, `gzip`, rec.Header().Get(echo.HeaderContentEncoding))
+	r, err := gzip.NewReader(rec.Body)
+	assert.NoError(t, err)
+	b, _ := ioutil.ReadAll(r)
+	assert.Equal(t, `test`, string(b))
+
+	RegisterEncoder(`br`, func(w io.Writer, level int) (io.WriteCloser, error) {
+		return &testBrotliWriter{Writer: w}, nil
+	})
+	defer func() {
+		encodersLock.Lock()
+		delete(encoders, `br`)
+		encodersLock.Unlock()
+	}()
+	rec = test.Request(echo.GET, "/", e, func(req *http.Request) {
+		req.Header.Set(echo.HeaderAcceptEncoding, `br, gzip;q=0.8`)
+	})
+	assert.Equal(t, `br`, rec.Header().Get(echo.HeaderContentEncoding))
+	assert.Equal(t, `br:test`, rec.Body.String())
+
+	rec = test.Request(echo.GET, "/", e)
+	assert.Empty(t, rec.Header().Get(echo.HeaderContentEncoding))
+	assert.Equal(t, `test`, rec.Body.String())
+}
+
+func TestGzipIgnoresOtherEncodings(t *testing.T) {
+	e := echo.New()
+	e.Use(Gzip())
+	e.Get("/", func(c echo.Context) error {
+		return c.String(`test`)
+	})
+	e.RebuildRouter()
+
+	rec := test.Request(echo.GET, "/", e, func(req *http.Request) {
+		req.Header.Set(echo.HeaderAcceptEncoding, `deflate`)
+	})
+	assert.Empty(t, rec.Header().Get(echo.HeaderContentEncoding))
+	assert.True(t, bytes.Equal([]byte(`test`), rec.Body.Bytes()))
+}
+
+func TestCompressVaryWithCORS(t *testing.T) {
+	e := echo.New()
+	e.Use(CORS(), Gzip())
+	e.Get("/", func(c echo.Context) error {
+		c.AddVary(echo.HeaderOrigin)
+		return c.String(`test`)
+	})
+	e.RebuildRouter()
+
+	rec := test.Request(echo.GET, "/", e, func(req *http.Request) {
+		req.Header.Set(echo.HeaderOrigin, `http://example.com`)
+		req.Header.Set(echo.HeaderAcceptEncoding, `gzip`)
+	})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, []string{`Origin, Accept-Encoding`}, rec.Header()[echo.HeaderVary])
+}
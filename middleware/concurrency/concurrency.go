@@ -0,0 +1,85 @@
+package concurrency
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/webx-top/echo"
+)
+
+type Config struct {
+	// Skipper defines a function to skip middleware.
+	Skipper echo.Skipper `json:"-"`
+
+	// Max is the maximum number of handlers executed concurrently.
+	Max int `json:"max"`
+
+	// Timeout is how long a request waits for a free slot before it is
+	// rejected. Optional. Default value 0, rejects immediately.
+	Timeout time.Duration `json:"timeout"`
+}
+
+var (
+	// DefaultConfig is the default concurrency middleware config.
+	DefaultConfig = Config{
+		Skipper: echo.DefaultSkipper,
+	}
+)
+
+// testHookWait is called when a request starts waiting for a free slot.
+var testHookWait = func() {}
+
+// Limit caps the number of handlers executed concurrently to n, the requests
+// exceeding the limit are rejected with 503 Service Unavailable.
+// Usage `Echo#Use(concurrency.Limit(10))`
+func Limit(n int) echo.MiddlewareFuncd {
+	config := DefaultConfig
+	config.Max = n
+	return LimitWithConfig(config)
+}
+
+// LimitWithConfig returns a concurrency middleware with config.
+func LimitWithConfig(config Config) echo.MiddlewareFuncd {
+	// Defaults
+	if config.Skipper == nil {
+		config.Skipper = DefaultConfig.Skipper
+	}
+	if config.Max <= 0 {
+		panic(`concurrency middleware requires a positive max`)
+	}
+	sem := make(chan struct{}, config.Max)
+
+	return func(next echo.Handler) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.Skipper(c) {
+				return next.Handle(c)
+			}
+			if !acquire(c, sem, config.Timeout) {
+				return echo.NewHTTPError(http.StatusServiceUnavailable)
+			}
+			defer func() { <-sem }()
+			return next.Handle(c)
+		}
+	}
+}
+
+func acquire(c echo.Context, sem chan struct{}, timeout time.Duration) bool {
+	select {
+	case sem <- struct{}{}:
+		return true
+	default:
+	}
+	if timeout <= 0 {
+		return false
+	}
+	t := time.NewTimer(timeout)
+	defer t.Stop()
+	testHookWait()
+	select {
+	case sem <- struct{}{}:
+		return true
+	case <-t.C:
+	case <-c.StdContext().Done():
+	}
+	return false
+}
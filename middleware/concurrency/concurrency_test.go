@@ -0,0 +1,107 @@
+package concurrency
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/webx-top/echo"
+	test "github.com/webx-top/echo/testing"
+)
+
+func serve(e *echo.Echo, wg *sync.WaitGroup, codes chan<- int) {
+	defer wg.Done()
+	codes <- test.Request(echo.GET, `/`, e).Code
+}
+
+func TestLimitReject(t *testing.T) {
+	e := echo.New()
+	started := make(chan struct{})
+	release := make(chan struct{})
+	e.Use(Limit(1))
+	e.Get(`/`, func(c echo.Context) error {
+		started <- struct{}{}
+		<-release
+		return c.String(`OK`)
+	})
+	e.RebuildRouter()
+
+	wg := &sync.WaitGroup{}
+	codes := make(chan int, 2)
+	wg.Add(1)
+	go serve(e, wg, codes)
+	<-started
+
+	rec := test.Request(echo.GET, `/`, e)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	close(release)
+	wg.Wait()
+	assert.Equal(t, http.StatusOK, <-codes)
+
+	// the slot is released after the handler returns
+	go func() { <-started }()
+	rec = test.Request(echo.GET, `/`, e)
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestLimitBlocking(t *testing.T) {
+	e := echo.New()
+	started := make(chan struct{}, 2)
+	release := make(chan struct{})
+	e.Use(LimitWithConfig(Config{Max: 1, Timeout: time.Second}))
+	e.Get(`/`, func(c echo.Context) error {
+		started <- struct{}{}
+		<-release
+		return c.String(`OK`)
+	})
+	e.RebuildRouter()
+	waiting := make(chan struct{}, 1)
+	testHookWait = func() {
+		waiting <- struct{}{}
+	}
+	defer func() {
+		testHookWait = func() {}
+	}()
+
+	wg := &sync.WaitGroup{}
+	codes := make(chan int, 2)
+	wg.Add(2)
+	go serve(e, wg, codes)
+	<-started
+	go serve(e, wg, codes)
+
+	// the second request waits for the first one instead of being rejected
+	<-waiting
+	close(release)
+	wg.Wait()
+	assert.Equal(t, http.StatusOK, <-codes)
+	assert.Equal(t, http.StatusOK, <-codes)
+}
+
+func TestLimitBlockingTimeout(t *testing.T) {
+	e := echo.New()
+	started := make(chan struct{})
+	release := make(chan struct{})
+	e.Use(LimitWithConfig(Config{Max: 1, Timeout: 20 * time.Millisecond}))
+	e.Get(`/`, func(c echo.Context) error {
+		started <- struct{}{}
+		<-release
+		return c.String(`OK`)
+	})
+	e.RebuildRouter()
+
+	wg := &sync.WaitGroup{}
+	codes := make(chan int, 1)
+	wg.Add(1)
+	go serve(e, wg, codes)
+	<-started
+
+	rec := test.Request(echo.GET, `/`, e)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	close(release)
+	wg.Wait()
+	assert.Equal(t, http.StatusOK, <-codes)
+}
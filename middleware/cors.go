@@ -91,7 +91,7 @@ func CORSWithConfig(config CORSConfig) echo.MiddlewareFunc {
 
 			// Simple request
 			if req.Method() != echo.OPTIONS {
-				header.Add(echo.HeaderVary, echo.HeaderOrigin)
+				c.AddVary(echo.HeaderOrigin)
 				header.Set(echo.HeaderAccessControlAllowOrigin, allowOrigins)
 				if config.AllowCredentials {
 					header.Set(echo.HeaderAccessControlAllowCredentials, "true")
@@ -103,9 +103,7 @@ func CORSWithConfig(config CORSConfig) echo.MiddlewareFunc {
 			}
 
 			// Preflight request
-			header.Add(echo.HeaderVary, echo.HeaderOrigin)
-			header.Add(echo.HeaderVary, echo.HeaderAccessControlRequestMethod)
-			header.Add(echo.HeaderVary, echo.HeaderAccessControlRequestHeaders)
+			c.AddVary(echo.HeaderOrigin, echo.HeaderAccessControlRequestMethod, echo.HeaderAccessControlRequestHeaders)
 			header.Set(echo.HeaderAccessControlAllowOrigin, allowOrigins)
 			header.Set(echo.HeaderAccessControlAllowMethods, allowMethods)
 			if config.AllowCredentials {
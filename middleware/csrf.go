@@ -157,7 +157,7 @@ func CSRFWithConfig(config CSRFConfig) echo.MiddlewareFuncd {
 			c.Set(config.ContextKey, token)
 
 			// Protect clients from caching the response
-			c.Response().Header().Add(echo.HeaderVary, echo.HeaderCookie)
+			c.AddVary(echo.HeaderCookie)
 
 			return next.Handle(c)
 		}
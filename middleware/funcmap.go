@@ -47,6 +47,7 @@ func FuncMap(funcMap map[string]interface{}, skipper ...echo.Skipper) echo.Middl
 			c.SetFunc(`URL`, req.URL)
 			c.SetFunc(`URI`, req.URI)
 			c.SetFunc(`Site`, c.Site)
+			c.SetFunc(`CSPNonce`, c.CSPNonce)
 
 			var pageURL string
 			c.SetFunc(`SiteURI`, func() string {
@@ -0,0 +1,58 @@
+// Package headerlimit rejects the requests carrying too many header fields.
+package headerlimit
+
+import (
+	"net/http"
+
+	"github.com/webx-top/echo"
+	"github.com/webx-top/echo/engine"
+)
+
+// Config defines the config for HeaderLimit middleware.
+type Config struct {
+	// Skipper defines a function to skip middleware.
+	Skipper echo.Skipper `json:"-"`
+
+	// Max is the maximum number of the request header fields.
+	Max int `json:"max"`
+}
+
+var (
+	// DefaultConfig is the default HeaderLimit middleware config.
+	DefaultConfig = Config{
+		Skipper: echo.DefaultSkipper,
+		Max:     100,
+	}
+)
+
+// MaxHeaders rejects the requests carrying more than n header fields with
+// 431 Request Header Fields Too Large.
+// Usage `Echo#Pre(headerlimit.MaxHeaders(50))`
+func MaxHeaders(n int) echo.MiddlewareFuncd {
+	config := DefaultConfig
+	config.Max = n
+	return MaxHeadersWithConfig(config)
+}
+
+// MaxHeadersWithConfig returns a HeaderLimit middleware with config.
+func MaxHeadersWithConfig(config Config) echo.MiddlewareFuncd {
+	// Defaults
+	if config.Skipper == nil {
+		config.Skipper = DefaultConfig.Skipper
+	}
+	if config.Max <= 0 {
+		config.Max = DefaultConfig.Max
+	}
+
+	return func(next echo.Handler) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.Skipper(c) {
+				return next.Handle(c)
+			}
+			if engine.HeaderCount(c.Request().Header()) > config.Max {
+				return echo.NewHTTPError(http.StatusRequestHeaderFieldsTooLarge)
+			}
+			return next.Handle(c)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package headerlimit
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/webx-top/echo"
+	test "github.com/webx-top/echo/testing"
+)
+
+func TestMaxHeaders(t *testing.T) {
+	e := echo.New()
+	e.Pre(MaxHeaders(3))
+	e.Get(`/`, func(c echo.Context) error {
+		return c.String(`OK`)
+	})
+	e.RebuildRouter()
+
+	withHeaders := func(n int) func(*http.Request) {
+		return func(req *http.Request) {
+			for i := 0; i < n; i++ {
+				req.Header.Add(`X-Test`, strconv.Itoa(i))
+			}
+		}
+	}
+	rec := test.Request(echo.GET, `/`, e, withHeaders(3))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = test.Request(echo.GET, `/`, e, withHeaders(4))
+	assert.Equal(t, http.StatusRequestHeaderFieldsTooLarge, rec.Code)
+}
@@ -0,0 +1,78 @@
+package httpsredirect
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/webx-top/echo"
+)
+
+type Config struct {
+	// Skipper defines a function to skip middleware.
+	Skipper echo.Skipper `json:"-"`
+
+	// Code is the status code used when redirecting.
+	// Optional. Default value http.StatusMovedPermanently.
+	Code int `json:"code"`
+
+	// ExcludePaths lists request paths that are never redirected, such as
+	// load balancer health checks.
+	// Optional. Default value ["/health", "/healthz"].
+	ExcludePaths []string `json:"exclude_paths"`
+}
+
+var (
+	// DefaultConfig is the default HTTPSRedirect middleware config.
+	DefaultConfig = Config{
+		Skipper:      echo.DefaultSkipper,
+		Code:         http.StatusMovedPermanently,
+		ExcludePaths: []string{`/health`, `/healthz`},
+	}
+)
+
+// HTTPSRedirect redirects plain HTTP requests to the HTTPS URL of the same host and path.
+// Usage `Echo#Pre(httpsredirect.HTTPSRedirect(http.StatusPermanentRedirect))`
+func HTTPSRedirect(code int) echo.MiddlewareFuncd {
+	config := DefaultConfig
+	config.Code = code
+	return HTTPSRedirectWithConfig(config)
+}
+
+// HTTPSRedirectWithConfig returns an HTTPSRedirect middleware with config.
+func HTTPSRedirectWithConfig(config Config) echo.MiddlewareFuncd {
+	// Defaults
+	if config.Skipper == nil {
+		config.Skipper = DefaultConfig.Skipper
+	}
+	if config.Code == 0 {
+		config.Code = DefaultConfig.Code
+	}
+	if config.ExcludePaths == nil {
+		config.ExcludePaths = DefaultConfig.ExcludePaths
+	}
+	return func(next echo.Handler) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.Skipper(c) || IsSecure(c) {
+				return next.Handle(c)
+			}
+			req := c.Request()
+			path := req.URL().Path()
+			for _, excluded := range config.ExcludePaths {
+				if path == excluded {
+					return next.Handle(c)
+				}
+			}
+			return c.Redirect(`https://`+req.Host()+req.URL().String(), config.Code)
+		}
+	}
+}
+
+// IsSecure reports whether the request was made over HTTPS, either directly
+// or through a proxy setting the `X-Forwarded-Proto` header.
+func IsSecure(c echo.Context) bool {
+	if c.IsSecure() {
+		return true
+	}
+	proto := c.Header(echo.HeaderXForwardedProto)
+	return strings.EqualFold(proto, `https`)
+}
@@ -0,0 +1,39 @@
+package httpsredirect
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/webx-top/echo"
+	test "github.com/webx-top/echo/testing"
+)
+
+func TestHTTPSRedirect(t *testing.T) {
+	e := echo.New()
+	e.Pre(HTTPSRedirect(http.StatusPermanentRedirect))
+	e.Get("/users", func(c echo.Context) error {
+		return c.String(`users`)
+	})
+	e.Get("/healthz", func(c echo.Context) error {
+		return c.String(`ok`)
+	})
+	e.RebuildRouter()
+
+	rec := test.Request(echo.GET, "/users?page=2", e, func(req *http.Request) {
+		req.Host = "example.com"
+	})
+	assert.Equal(t, http.StatusPermanentRedirect, rec.Code)
+	assert.Equal(t, "https://example.com/users?page=2", rec.Header().Get(echo.HeaderLocation))
+
+	rec = test.Request(echo.GET, "/users", e, func(req *http.Request) {
+		req.Host = "example.com"
+		req.Header.Set(echo.HeaderXForwardedProto, "https")
+	})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "users", rec.Body.String())
+
+	rec = test.Request(echo.GET, "/healthz", e)
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
@@ -0,0 +1,144 @@
+package ipfilter
+
+import (
+	"net"
+	"strings"
+
+	"github.com/webx-top/echo"
+	"github.com/webx-top/echo/engine"
+)
+
+// ListConfig defines the config for the AllowList and BlockList middleware.
+type ListConfig struct {
+	// Skipper defines a function to skip middleware.
+	Skipper echo.Skipper `json:"-"`
+
+	// CIDRs of the client IPs, e.g. `10.0.0.0/8` or `2001:db8::1`.
+	CIDRs []string `json:"cidrs"`
+
+	// Allow permits only the client IPs in CIDRs when true, otherwise it denies them.
+	Allow bool `json:"allow"`
+
+	// TrustedProxies are the CIDRs of the proxies whose `X-Forwarded-For` and
+	// `X-Real-IP` headers are trusted.
+	// Optional. Default value none, the client IP is the remote address.
+	TrustedProxies []string `json:"trusted_proxies"`
+}
+
+// AllowList permits only the requests whose client IP is in one of the CIDRs,
+// the others get 403 Forbidden. Both IPv4 and IPv6 CIDRs are supported, a
+// single IP matches only itself. The forwarding headers are not trusted, see
+// `ListConfig.TrustedProxies`.
+// Usage `Echo#Use(ipfilter.AllowList("10.0.0.0/8", "2001:db8::/32"))`
+func AllowList(cidrs ...string) echo.MiddlewareFuncd {
+	return ListWithConfig(ListConfig{CIDRs: cidrs, Allow: true})
+}
+
+// BlockList denies the requests whose client IP is in one of the CIDRs with
+// 403 Forbidden, the others are permitted.
+func BlockList(cidrs ...string) echo.MiddlewareFuncd {
+	return ListWithConfig(ListConfig{CIDRs: cidrs})
+}
+
+// ListWithConfig returns an AllowList or BlockList middleware with config.
+func ListWithConfig(config ListConfig) echo.MiddlewareFuncd {
+	// Defaults
+	if config.Skipper == nil {
+		config.Skipper = echo.DefaultSkipper
+	}
+	nets := MustParseCIDRs(config.CIDRs...)
+	trusted := MustParseCIDRs(config.TrustedProxies...)
+	return func(next echo.Handler) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.Skipper(c) {
+				return next.Handle(c)
+			}
+			if Contains(nets, net.ParseIP(ClientIP(c, trusted))) != config.Allow {
+				return echo.ErrForbidden
+			}
+			return next.Handle(c)
+		}
+	}
+}
+
+// ParseCIDRs parses the CIDRs, e.g. `192.168.0.0/16` or `::1`. A single IP is
+// parsed as a /32 (IPv4) or /128 (IPv6) network.
+func ParseCIDRs(cidrs ...string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		cidr = strings.TrimSpace(cidr)
+		if !strings.Contains(cidr, `/`) {
+			ip := net.ParseIP(cidr)
+			if ip == nil {
+				return nil, &net.ParseError{Type: `IP address`, Text: cidr}
+			}
+			if ip4 := ip.To4(); ip4 != nil {
+				nets = append(nets, &net.IPNet{IP: ip4, Mask: net.CIDRMask(32, 32)})
+			} else {
+				nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)})
+			}
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, err
+		}
+		nets = append(nets, ipNet)
+	}
+	return nets, nil
+}
+
+// MustParseCIDRs is like ParseCIDRs but panics if a CIDR is invalid.
+func MustParseCIDRs(cidrs ...string) []*net.IPNet {
+	nets, err := ParseCIDRs(cidrs...)
+	if err != nil {
+		panic(err)
+	}
+	return nets
+}
+
+// Contains reports whether ip is in one of the networks.
+func Contains(nets []*net.IPNet, ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	for _, ipNet := range nets {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP returns the IP of the client. The forwarding headers are only used
+// when the remote address is one of the trusted proxies: the client IP is then
+// the last address of `X-Forwarded-For` which is not a trusted proxy, as the
+// addresses before it may be spoofed, or else `X-Real-IP`.
+func ClientIP(c echo.Context, trustedProxies []*net.IPNet) string {
+	req := c.Request()
+	ip := engine.AddressIP(req.RemoteAddress())
+	if !Contains(trustedProxies, net.ParseIP(ip)) {
+		return ip
+	}
+	header := req.Header()
+	if xff := header.Get(echo.HeaderXForwardedFor); len(xff) > 0 {
+		for _, addr := range reversed(strings.Split(xff, `,`)) {
+			ip = engine.AddressIP(addr)
+			if !Contains(trustedProxies, net.ParseIP(ip)) {
+				break
+			}
+		}
+		return ip
+	}
+	if realIP := header.Get(echo.HeaderXRealIP); len(realIP) > 0 {
+		return engine.AddressIP(realIP)
+	}
+	return ip
+}
+
+func reversed(s []string) []string {
+	for i, j := 0, len(s)-1; i < j; i, j = i+1, j-1 {
+		s[i], s[j] = s[j], s[i]
+	}
+	return s
+}
@@ -0,0 +1,70 @@
+package ipfilter
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/webx-top/echo"
+	test "github.com/webx-top/echo/testing"
+)
+
+func newEcho(m echo.MiddlewareFuncd) *echo.Echo {
+	e := echo.New()
+	e.Use(m)
+	e.Get(`/`, func(c echo.Context) error {
+		return c.String(`OK`)
+	})
+	e.RebuildRouter()
+	return e
+}
+
+func requestFrom(e *echo.Echo, remoteAddr string) int {
+	return test.Request(echo.GET, `/`, e, func(req *http.Request) {
+		req.RemoteAddr = remoteAddr
+	}).Code
+}
+
+func TestAllowList(t *testing.T) {
+	e := newEcho(AllowList(`192.168.1.0/24`, `2001:db8::/32`))
+	assert.Equal(t, http.StatusOK, requestFrom(e, `192.168.1.20:1234`))
+	assert.Equal(t, http.StatusOK, requestFrom(e, `[2001:db8::1]:1234`))
+	assert.Equal(t, http.StatusForbidden, requestFrom(e, `192.168.2.1:1234`))
+	assert.Equal(t, http.StatusForbidden, requestFrom(e, `[2001:db9::1]:1234`))
+
+	// a spoofed X-Forwarded-For from an untrusted peer is ignored
+	forwarded := func(remoteAddr string, xff string) int {
+		return test.Request(echo.GET, `/`, e, func(req *http.Request) {
+			req.RemoteAddr = remoteAddr
+			req.Header.Set(echo.HeaderXForwardedFor, xff)
+		}).Code
+	}
+	assert.Equal(t, http.StatusForbidden, forwarded(`10.0.0.1:1234`, `192.168.1.5`))
+
+	e = newEcho(ListWithConfig(ListConfig{
+		CIDRs:          []string{`192.168.1.0/24`},
+		Allow:          true,
+		TrustedProxies: []string{`10.0.0.0/8`},
+	}))
+	assert.Equal(t, http.StatusOK, forwarded(`10.0.0.1:1234`, `192.168.1.5, 10.0.0.2`))
+	// the client can only prepend addresses to the ones added by the proxies
+	assert.Equal(t, http.StatusForbidden, forwarded(`10.0.0.1:1234`, `192.168.1.5, 172.16.0.1`))
+	assert.Equal(t, http.StatusForbidden, forwarded(`172.16.0.1:1234`, `192.168.1.5`))
+}
+
+func TestBlockList(t *testing.T) {
+	e := newEcho(BlockList(`192.168.1.20`, `::1`))
+	assert.Equal(t, http.StatusForbidden, requestFrom(e, `192.168.1.20:1234`))
+	assert.Equal(t, http.StatusForbidden, requestFrom(e, `[::1]:1234`))
+	assert.Equal(t, http.StatusOK, requestFrom(e, `192.168.1.21:1234`))
+}
+
+func TestParseCIDRs(t *testing.T) {
+	_, err := ParseCIDRs(`192.168.1.0/33`)
+	assert.Error(t, err)
+	_, err = ParseCIDRs(`not-an-ip`)
+	assert.Error(t, err)
+	nets, err := ParseCIDRs(` 10.0.0.0/8 `, `fe80::1`)
+	assert.NoError(t, err)
+	assert.Len(t, nets, 2)
+}
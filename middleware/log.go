@@ -4,11 +4,18 @@ import (
 	"fmt"
 	"io"
 	std "log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/webx-top/echo"
 )
 
+// logNow returns the time the latency of the requests is measured with.
+var logNow = time.Now
+
 type VisitorInfo struct {
 	RealIP       string
 	Time         time.Time
@@ -22,33 +29,108 @@ type VisitorInfo struct {
 	RequestSize  int64
 	ResponseSize int64
 	ResponseCode int
+	Header       http.Header // request header with the redacted values masked
+	Slow         bool        // the latency exceeds LogConfig.SlowThreshold
+}
+
+// String returns the access log line of the default logging.
+func (v *VisitorInfo) String() string {
+	return ":" + fmt.Sprint(v.ResponseCode) + ": " + v.RealIP + " " + v.Method + " " + v.Scheme + " " + v.Host + " " + v.URI + " " + v.Elapsed.String() + " " + fmt.Sprint(v.ResponseSize)
 }
 
-var DefaultLogWriter = GetDefaultLogWriter()
+// LogConfig defines the config for Log middleware.
+type LogConfig struct {
+	// Skipper defines a function to skip middleware.
+	Skipper echo.Skipper `json:"-"`
+
+	// Writer of the default logging.
+	// Optional. Default value DefaultLogWriter.
+	Writer io.Writer `json:"-"`
+
+	// Receiver receives the visitor info instead of the default logging.
+	// Optional.
+	Receiver func(*VisitorInfo) `json:"-"`
+
+	// RedactHeaders are the request headers whose values are masked.
+	// Optional. Default value DefaultRedactHeaders.
+	RedactHeaders []string `json:"redactHeaders"`
+
+	// RedactQueries are the query params whose values are masked in `VisitorInfo.URI`.
+	// Optional.
+	RedactQueries []string `json:"redactQueries"`
+
+	// SlowThreshold marks the requests whose latency exceeds it as slow. Without
+	// Writer and Receiver, the requests are logged to the Echo logger: at Warn
+	// level when slow, otherwise at Debug. Otherwise they are all passed to
+	// Writer or Receiver, `VisitorInfo.Slow` marks the slow ones and the default
+	// logging prefixes them with "slow request: ".
+	// Optional. Default value 0 (disabled).
+	SlowThreshold time.Duration `json:"slowThreshold"`
+}
+
+// RedactedMask replaces the redacted values in the access log.
+const RedactedMask = `******`
+
+var (
+	DefaultLogWriter = GetDefaultLogWriter()
+
+	// DefaultRedactHeaders are the request headers masked by default.
+	DefaultRedactHeaders = []string{
+		echo.HeaderAuthorization,
+		echo.HeaderCookie,
+		`Proxy-Authorization`,
+	}
+
+	// DefaultLogConfig is the default Log middleware config.
+	DefaultLogConfig = LogConfig{
+		Skipper: echo.DefaultSkipper,
+	}
+)
 
 func Log(recv ...func(*VisitorInfo)) echo.MiddlewareFunc {
 	return LogWithWriter(nil, recv...)
 }
 
 func LogWithWriter(writer io.Writer, recv ...func(*VisitorInfo)) echo.MiddlewareFunc {
-	var logging func(*VisitorInfo)
+	config := DefaultLogConfig
+	config.Writer = writer
 	if len(recv) > 0 {
-		logging = recv[0]
+		config.Receiver = recv[0]
 	}
+	return LogWithConfig(config)
+}
+
+// LogWithConfig returns an access log middleware with config.
+func LogWithConfig(config LogConfig) echo.MiddlewareFunc {
+	if config.Skipper == nil {
+		config.Skipper = DefaultLogConfig.Skipper
+	}
+	if config.RedactHeaders == nil {
+		config.RedactHeaders = DefaultRedactHeaders
+	}
+	writer := config.Writer
 	if writer == nil {
 		writer = DefaultLogWriter
 	}
+	logging := config.Receiver
 	logger := std.New(writer, ``, 0)
 	if logging == nil {
 		logging = func(v *VisitorInfo) {
-			logger.Println(":" + fmt.Sprint(v.ResponseCode) + ": " + v.RealIP + " " + v.Method + " " + v.Scheme + " " + v.Host + " " + v.URI + " " + v.Elapsed.String() + " " + fmt.Sprint(v.ResponseSize))
+			if v.Slow {
+				logger.Println(`slow request: ` + v.String())
+				return
+			}
+			logger.Println(v.String())
 		}
 	}
 	return func(h echo.Handler) echo.Handler {
 		return echo.HandlerFunc(func(c echo.Context) error {
+			if config.Skipper(c) {
+				return h.Handle(c)
+			}
 			req := c.Request()
 			res := c.Response()
-			info := &VisitorInfo{Time: time.Now()}
+			info := &VisitorInfo{Time: logNow()}
 			if err := h.Handle(c); err != nil {
 				c.Error(err)
 			}
@@ -56,15 +138,80 @@ func LogWithWriter(writer io.Writer, recv ...func(*VisitorInfo)) echo.Middleware
 			info.UserAgent = req.UserAgent()
 			info.Referer = req.Referer()
 			info.RequestSize = req.Size()
-			info.Elapsed = time.Now().Sub(info.Time)
+			info.Elapsed = logNow().Sub(info.Time)
 			info.Method = req.Method()
 			info.Host = req.Host()
 			info.Scheme = req.Scheme()
-			info.URI = req.URI()
+			info.URI = redactQuery(req.URI(), config.RedactQueries)
+			info.Header = redactHeader(req.Header().Std(), config.RedactHeaders)
 			info.ResponseSize = res.Size()
 			info.ResponseCode = res.Status()
+			if config.SlowThreshold > 0 {
+				info.Slow = info.Elapsed > config.SlowThreshold
+			}
+			if config.SlowThreshold > 0 && config.Receiver == nil && config.Writer == nil {
+				if info.Slow {
+					c.Logger().Warn(`slow request: `, info.String())
+				} else {
+					c.Logger().Debug(info.String())
+				}
+				return nil
+			}
 			logging(info)
 			return nil
 		})
 	}
 }
+
+func redactHeader(header http.Header, names []string) http.Header {
+	cloned := make(http.Header, len(header))
+	for key, values := range header {
+		cloned[key] = append([]string(nil), values...)
+	}
+	for _, name := range names {
+		name = http.CanonicalHeaderKey(name)
+		if _, ok := cloned[name]; ok {
+			cloned[name] = []string{RedactedMask}
+		}
+	}
+	return cloned
+}
+
+func redactQuery(uri string, names []string) string {
+	if len(names) == 0 {
+		return uri
+	}
+	pos := strings.Index(uri, `?`)
+	if pos < 0 {
+		return uri
+	}
+	query, err := url.ParseQuery(uri[pos+1:])
+	if err != nil {
+		return uri[:pos]
+	}
+	masked := make(map[string]bool, len(names))
+	for _, name := range names {
+		if _, ok := query[name]; ok {
+			masked[name] = true
+		}
+	}
+	if len(masked) == 0 {
+		return uri
+	}
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if masked[key] {
+			parts = append(parts, url.QueryEscape(key)+`=`+RedactedMask)
+			continue
+		}
+		for _, value := range query[key] {
+			parts = append(parts, url.QueryEscape(key)+`=`+url.QueryEscape(value))
+		}
+	}
+	return uri[:pos+1] + strings.Join(parts, `&`)
+}
@@ -0,0 +1,141 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/webx-top/echo"
+	"github.com/webx-top/echo/logger"
+	test "github.com/webx-top/echo/testing"
+)
+
+func TestLogRedaction(t *testing.T) {
+	e := echo.New()
+	var info *VisitorInfo
+	e.Use(LogWithConfig(LogConfig{
+		Receiver: func(v *VisitorInfo) {
+			info = v
+		},
+		RedactQueries: []string{`token`},
+	}))
+	e.Get("/", func(c echo.Context) error {
+		return c.String(c.Request().Header().Get(echo.HeaderAuthorization))
+	})
+	e.RebuildRouter()
+
+	rec := test.Request(echo.GET, "/?token=secret&page=2", e, func(req *http.Request) {
+		req.RequestURI = req.URL.RequestURI()
+		req.Header.Set(echo.HeaderAuthorization, `Bearer secret`)
+		req.Header.Set(`User-Agent`, `echo-test`)
+	})
+	assert.Equal(t, `Bearer secret`, rec.Body.String())
+	if assert.NotNil(t, info) {
+		assert.Equal(t, RedactedMask, info.Header.Get(echo.HeaderAuthorization))
+		assert.Equal(t, `echo-test`, info.Header.Get(`User-Agent`))
+		assert.Equal(t, `/?page=2&token=`+RedactedMask, info.URI)
+	}
+}
+
+func TestLogWithWriter(t *testing.T) {
+	e := echo.New()
+	buf := new(bytes.Buffer)
+	e.Use(LogWithWriter(buf))
+	e.Get("/", func(c echo.Context) error {
+		return c.String(`OK`)
+	})
+	e.RebuildRouter()
+
+	test.Request(echo.GET, "/", e)
+	assert.Contains(t, buf.String(), `:200: `)
+}
+
+type levelLogger struct {
+	logger.Base
+	levels []string
+}
+
+func (l *levelLogger) Debug(...interface{}) {
+	l.levels = append(l.levels, `debug`)
+}
+
+func (l *levelLogger) Warn(...interface{}) {
+	l.levels = append(l.levels, `warn`)
+}
+
+func TestLogSlowThreshold(t *testing.T) {
+	defer useLogClock()()
+	e := echo.New()
+	l := &levelLogger{}
+	e.SetLogger(l)
+	e.Use(LogWithConfig(LogConfig{
+		SlowThreshold: 20 * time.Millisecond,
+	}))
+	addSlowRoutes(e)
+
+	test.Request(echo.GET, "/fast", e)
+	test.Request(echo.GET, "/slow", e)
+	assert.Equal(t, []string{`debug`, `warn`}, l.levels)
+}
+
+func TestLogSlowThresholdWithWriter(t *testing.T) {
+	defer useLogClock()()
+	e := echo.New()
+	l := &levelLogger{}
+	e.SetLogger(l)
+	buf := new(bytes.Buffer)
+	var slow []bool
+	g := e.Group(`/recv`, LogWithConfig(LogConfig{
+		Receiver: func(v *VisitorInfo) {
+			slow = append(slow, v.Slow)
+		},
+		SlowThreshold: 20 * time.Millisecond,
+	}))
+	addSlowRoutes(g)
+	w := e.Group(`/writer`, LogWithConfig(LogConfig{
+		Writer:        buf,
+		SlowThreshold: 20 * time.Millisecond,
+	}))
+	addSlowRoutes(w)
+	e.RebuildRouter()
+
+	test.Request(echo.GET, "/recv/fast", e)
+	test.Request(echo.GET, "/recv/slow", e)
+	assert.Equal(t, []bool{false, true}, slow)
+
+	test.Request(echo.GET, "/writer/fast", e)
+	test.Request(echo.GET, "/writer/slow", e)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if assert.Len(t, lines, 2) {
+		assert.True(t, strings.HasPrefix(lines[0], `:200: `))
+		assert.True(t, strings.HasPrefix(lines[1], `slow request: :200: `))
+	}
+	assert.Empty(t, l.levels)
+}
+
+// logClock is the time of the log middleware during the tests using
+// useLogClock, it only moves on in the slow handler of addSlowRoutes.
+var logClock time.Time
+
+func useLogClock() (restore func()) {
+	logNow = func() time.Time {
+		return logClock
+	}
+	return func() {
+		logNow = time.Now
+	}
+}
+
+func addSlowRoutes(r echo.RouteRegister) {
+	r.Get("/fast", func(c echo.Context) error {
+		return c.String(`fast`)
+	})
+	r.Get("/slow", func(c echo.Context) error {
+		logClock = logClock.Add(40 * time.Millisecond)
+		return c.String(`slow`)
+	})
+}
@@ -0,0 +1,46 @@
+package maintenance
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/webx-top/echo"
+)
+
+// Maintenance responds 503 Service Unavailable with a `Retry-After` header to
+// all requests while enabled is true. The allowlist holds request paths which
+// are still served, a trailing `*` matches by prefix (e.g. `/admin/*`).
+// enabled can be toggled at runtime.
+func Maintenance(enabled *atomic.Bool, retryAfter time.Duration, allowlist ...string) echo.MiddlewareFuncd {
+	retryAfterSeconds := strconv.Itoa(int(retryAfter / time.Second))
+	return func(next echo.Handler) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if !enabled.Load() || IsAllowed(c.Request().URL().Path(), allowlist) {
+				return next.Handle(c)
+			}
+			if retryAfter > 0 {
+				c.Response().Header().Set(echo.HeaderRetryAfter, retryAfterSeconds)
+			}
+			return echo.NewHTTPError(http.StatusServiceUnavailable)
+		}
+	}
+}
+
+// IsAllowed reports whether the path matches one of the allowlist entries.
+func IsAllowed(path string, allowlist []string) bool {
+	for _, allowed := range allowlist {
+		if strings.HasSuffix(allowed, `*`) {
+			if strings.HasPrefix(path, strings.TrimSuffix(allowed, `*`)) {
+				return true
+			}
+			continue
+		}
+		if path == allowed {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,46 @@
+package maintenance
+
+import (
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/webx-top/echo"
+	test "github.com/webx-top/echo/testing"
+)
+
+func TestMaintenance(t *testing.T) {
+	e := echo.New()
+	enabled := &atomic.Bool{}
+	e.Use(Maintenance(enabled, 2*time.Minute, `/admin/*`))
+	e.Get("/", func(c echo.Context) error {
+		return c.String(`home`)
+	})
+	e.Get("/admin/maintenance", func(c echo.Context) error {
+		enabled.Store(!enabled.Load())
+		return c.String(`toggled`)
+	})
+	e.RebuildRouter()
+
+	rec := test.Request(echo.GET, "/", e)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Header().Get(echo.HeaderRetryAfter))
+
+	rec = test.Request(echo.GET, "/admin/maintenance", e)
+	assert.Equal(t, `toggled`, rec.Body.String())
+	assert.True(t, enabled.Load())
+
+	rec = test.Request(echo.GET, "/", e)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, `120`, rec.Header().Get(echo.HeaderRetryAfter))
+
+	rec = test.Request(echo.GET, "/admin/maintenance", e)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.False(t, enabled.Load())
+
+	rec = test.Request(echo.GET, "/", e)
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
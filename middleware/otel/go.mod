@@ -0,0 +1,13 @@
+module github.com/webx-top/echo/middleware/otel
+
+go 1.23
+
+require (
+	github.com/stretchr/testify v1.9.0
+	github.com/webx-top/echo v0.0.0-00010101000000-000000000000
+	go.opentelemetry.io/otel v1.44.0
+	go.opentelemetry.io/otel/sdk v1.44.0
+	go.opentelemetry.io/otel/trace v1.44.0
+)
+
+replace github.com/webx-top/echo => ../../
@@ -0,0 +1,103 @@
+// Package otel provides an OpenTelemetry tracing middleware. It is a separate
+// Go module, so that the OpenTelemetry dependencies are only required by the
+// applications using it.
+package otel
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/webx-top/echo"
+)
+
+const tracerName = "github.com/webx-top/echo/middleware/otel"
+
+type (
+	// Config defines the config for OpenTelemetry middleware.
+	Config struct {
+		// Skipper defines a function to skip middleware.
+		Skipper echo.Skipper `json:"-"`
+
+		// TracerProvider creates the tracer.
+		// Optional. Default value otel.GetTracerProvider().
+		TracerProvider trace.TracerProvider `json:"-"`
+
+		// Propagator extracts the incoming trace context.
+		// Optional. Default value propagation.TraceContext{} (W3C traceparent).
+		Propagator propagation.TextMapPropagator `json:"-"`
+	}
+)
+
+var (
+	// DefaultConfig is the default OpenTelemetry middleware config.
+	DefaultConfig = Config{
+		Skipper: echo.DefaultSkipper,
+	}
+)
+
+// Middleware returns a middleware which starts a server span per request,
+// named by the route template, and injects it into `Context.StdContext()`.
+func Middleware(tp ...trace.TracerProvider) echo.MiddlewareFunc {
+	config := DefaultConfig
+	if len(tp) > 0 {
+		config.TracerProvider = tp[0]
+	}
+	return MiddlewareWithConfig(config)
+}
+
+// MiddlewareWithConfig returns an OpenTelemetry middleware with config.
+// See: `Middleware()`.
+func MiddlewareWithConfig(config Config) echo.MiddlewareFunc {
+	// Defaults
+	if config.Skipper == nil {
+		config.Skipper = DefaultConfig.Skipper
+	}
+	if config.TracerProvider == nil {
+		config.TracerProvider = otel.GetTracerProvider()
+	}
+	if config.Propagator == nil {
+		config.Propagator = propagation.TraceContext{}
+	}
+	tracer := config.TracerProvider.Tracer(tracerName)
+
+	return func(h echo.Handler) echo.Handler {
+		return echo.HandlerFunc(func(c echo.Context) error {
+			if config.Skipper(c) {
+				return h.Handle(c)
+			}
+			req := c.Request()
+			ctx := config.Propagator.Extract(c.StdContext(), propagation.HeaderCarrier(req.Header().Std()))
+			name := c.Path()
+			if len(name) == 0 {
+				name = `HTTP ` + req.Method()
+			}
+			ctx, span := tracer.Start(ctx, name,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					attribute.String(`http.method`, req.Method()),
+					attribute.String(`http.route`, c.Path()),
+					attribute.String(`http.target`, req.URL().String()),
+					attribute.String(`http.host`, req.Host()),
+				),
+			)
+			defer span.End()
+			c.SetStdContext(ctx)
+
+			if err := h.Handle(c); err != nil {
+				span.RecordError(err)
+				c.Error(err)
+			}
+			status := c.Response().Status()
+			span.SetAttributes(attribute.Int(`http.status_code`, status))
+			if status >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(status))
+			}
+			return nil
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package otel
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/webx-top/echo"
+	test "github.com/webx-top/echo/testing"
+)
+
+func TestMiddleware(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	e := echo.New()
+	e.Use(Middleware(tp))
+	var traceID trace.TraceID
+	e.Get("/users/:id", func(c echo.Context) error {
+		traceID = trace.SpanContextFromContext(c.StdContext()).TraceID()
+		return c.String(c.Param(`id`))
+	})
+	e.Get("/fail", func(c echo.Context) error {
+		return errors.New(`failed`)
+	})
+	e.RebuildRouter()
+
+	rec := test.Request(echo.GET, "/users/1", e, func(req *http.Request) {
+		req.Header.Set(`traceparent`, `00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01`)
+	})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	spans := exporter.GetSpans()
+	if assert.Len(t, spans, 1) {
+		assert.Equal(t, `/users/:id`, spans[0].Name)
+		assert.Equal(t, trace.SpanKindServer, spans[0].SpanKind)
+		assert.Equal(t, `4bf92f3577b34da6a3ce929d0e0e4736`, spans[0].SpanContext.TraceID().String())
+		assert.Equal(t, `00f067aa0ba902b7`, spans[0].Parent.SpanID().String())
+		assert.Contains(t, spans[0].Attributes, attribute.Int(`http.status_code`, http.StatusOK))
+		assert.Equal(t, codes.Unset, spans[0].Status.Code)
+	}
+	assert.Equal(t, `4bf92f3577b34da6a3ce929d0e0e4736`, traceID.String())
+
+	exporter.Reset()
+	rec = test.Request(echo.GET, "/fail", e)
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	spans = exporter.GetSpans()
+	if assert.Len(t, spans, 1) {
+		assert.Equal(t, `/fail`, spans[0].Name)
+		assert.Equal(t, codes.Error, spans[0].Status.Code)
+		assert.Len(t, spans[0].Events, 1)
+	}
+}
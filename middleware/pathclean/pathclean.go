@@ -0,0 +1,91 @@
+package pathclean
+
+import (
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/webx-top/echo"
+)
+
+type Config struct {
+	// Skipper defines a function to skip middleware.
+	Skipper echo.Skipper `json:"-"`
+
+	// Status code to be used when redirecting the request.
+	// Optional, but when provided the request is redirected to the clean path
+	// using this code instead of being routed with it.
+	RedirectCode int `json:"redirect_code"`
+}
+
+var (
+	// DefaultConfig is the default PathClean middleware config.
+	DefaultConfig = Config{
+		Skipper: echo.DefaultSkipper,
+	}
+)
+
+// PathClean returns a root level (before router) middleware which normalizes
+// the request `URL#Path` with `path.Clean`, e.g. `/a//b/../c` is routed as `/a/c`.
+// A trailing slash is kept.
+//
+// Usage `Echo#Pre(pathclean.PathClean())`
+func PathClean() echo.MiddlewareFuncd {
+	return PathCleanWithConfig(DefaultConfig)
+}
+
+// PathCleanWithConfig returns a PathClean middleware with config.
+func PathCleanWithConfig(config Config) echo.MiddlewareFuncd {
+	// Defaults
+	if config.Skipper == nil {
+		config.Skipper = DefaultConfig.Skipper
+	}
+
+	return func(next echo.Handler) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.Skipper(c) {
+				return next.Handle(c)
+			}
+
+			req := c.Request()
+			u := req.URL()
+			p := u.Path()
+			cleaned := Clean(p)
+			if cleaned != p {
+				// The decoded path may contain `?` or `#`, escape it again.
+				uri := (&url.URL{Path: cleaned}).EscapedPath()
+				if qs := u.RawQuery(); len(qs) > 0 {
+					uri += "?" + qs
+				}
+
+				// Redirect
+				if config.RedirectCode != 0 {
+					return c.Redirect(uri, config.RedirectCode)
+				}
+
+				// Forward
+				req.SetURI(uri)
+				u.SetPath(cleaned)
+			}
+			return next.Handle(c)
+		}
+	}
+}
+
+// Clean returns the shortest path equivalent to p by `path.Clean`, it is
+// rooted and keeps the trailing slash of p. The leading slashes and backslashes
+// are collapsed to one slash, so that the path can not be taken for the
+// protocol-relative URL of another host, e.g. `/\evil.com`.
+func Clean(p string) string {
+	if len(p) == 0 {
+		return "/"
+	}
+	if p[0] != '/' {
+		p = "/" + p
+	}
+	cleaned := "/" + strings.TrimLeft(path.Clean(p), `/\`)
+	if p[len(p)-1] == '/' && cleaned != "/" {
+		cleaned += "/"
+	}
+	return cleaned
+}
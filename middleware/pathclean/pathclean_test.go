@@ -0,0 +1,70 @@
+package pathclean
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/webx-top/echo"
+	test "github.com/webx-top/echo/testing"
+)
+
+func TestClean(t *testing.T) {
+	assert.Equal(t, "/a/c", Clean("/a//b/../c"))
+	assert.Equal(t, "/b", Clean("/./a/../b"))
+	assert.Equal(t, "/a/", Clean("//a//"))
+	assert.Equal(t, "/", Clean("/../.."))
+	assert.Equal(t, "/", Clean(""))
+	assert.Equal(t, "/evil.com", Clean(`/.//\evil.com`))
+	assert.Equal(t, "/evil.com/", Clean(`\\evil.com/`))
+}
+
+func TestPathClean(t *testing.T) {
+	e := echo.New()
+	e.Pre(PathClean())
+	e.Get("/a/c", func(c echo.Context) error {
+		return c.String(c.Request().URL().Path() + "?" + c.QueryString())
+	})
+	e.RebuildRouter()
+
+	rec := test.Request(echo.GET, "/a//b/../c?x=1", e)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "/a/c?x=1", rec.Body.String())
+}
+
+func TestPathCleanRedirect(t *testing.T) {
+	e := echo.New()
+	e.Pre(PathCleanWithConfig(Config{RedirectCode: http.StatusMovedPermanently}))
+	e.Get("/a/c", func(c echo.Context) error {
+		return c.String("OK")
+	})
+	e.RebuildRouter()
+
+	rec := test.Request(echo.GET, "/a//b/../c?x=1", e)
+	assert.Equal(t, http.StatusMovedPermanently, rec.Code)
+	assert.Equal(t, "/a/c?x=1", rec.Header().Get(echo.HeaderLocation))
+
+	rec = test.Request(echo.GET, "/a/c", e)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = test.Request(echo.GET, "/.//%5Cevil.com", e)
+	assert.Equal(t, http.StatusMovedPermanently, rec.Code)
+	assert.Equal(t, "/evil.com", rec.Header().Get(echo.HeaderLocation))
+
+	rec = test.Request(echo.GET, "/a//b%3Fc?x=1", e)
+	assert.Equal(t, http.StatusMovedPermanently, rec.Code)
+	assert.Equal(t, "/a/b%3Fc?x=1", rec.Header().Get(echo.HeaderLocation))
+}
+
+func TestPathCleanEscapedURI(t *testing.T) {
+	e := echo.New()
+	e.Pre(PathClean())
+	e.Get("/a/*", func(c echo.Context) error {
+		return c.String(c.Request().URI() + " " + c.Request().URL().Path())
+	})
+	e.RebuildRouter()
+
+	rec := test.Request(echo.GET, "/a//b%3Fc?x=1", e)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "/a/b%3Fc?x=1 /a/b?c", rec.Body.String())
+}
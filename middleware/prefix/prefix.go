@@ -0,0 +1,53 @@
+package prefix
+
+import (
+	"strings"
+
+	"github.com/webx-top/echo"
+	"github.com/webx-top/echo/middleware"
+)
+
+// StripPrefix removes the prefix from the request path before routing, so an
+// application mounted behind a reverse proxy at `/app` routes `/app/users` to
+// `/users`. Requests outside the prefix are answered with 404 Not Found.
+// It should be registered with `Echo.Pre`.
+func StripPrefix(prefix string) echo.MiddlewareFuncd {
+	prefix = strings.TrimRight(prefix, `/`)
+	return func(next echo.Handler) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if len(prefix) == 0 {
+				return next.Handle(c)
+			}
+			u := c.Request().URL()
+			p := u.Path()
+			switch {
+			case p == prefix:
+				u.SetPath(`/`)
+			case strings.HasPrefix(p, prefix+`/`):
+				u.SetPath(p[len(prefix):])
+			default:
+				return echo.ErrNotFound
+			}
+			return next.Handle(c)
+		}
+	}
+}
+
+// AddPrefix prepends the prefix to the request path before routing.
+// It should be registered with `Echo.Pre`.
+func AddPrefix(prefix string) echo.MiddlewareFuncd {
+	prefix = strings.TrimRight(prefix, `/`)
+	return func(next echo.Handler) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			u := c.Request().URL()
+			u.SetPath(prefix + u.Path())
+			return next.Handle(c)
+		}
+	}
+}
+
+// Rewrite rewrites the request path by the rules, see `middleware.Rewrite`.
+// It should be registered with `Echo.Pre`.
+func Rewrite(rules map[string]string) echo.MiddlewareFuncd {
+	return middleware.Rewrite(rules)
+}
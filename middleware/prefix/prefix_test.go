@@ -0,0 +1,48 @@
+package prefix
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/webx-top/echo"
+	test "github.com/webx-top/echo/testing"
+)
+
+func TestStripPrefix(t *testing.T) {
+	e := echo.New()
+	e.Pre(StripPrefix(`/app/`))
+	e.Get("/", func(c echo.Context) error {
+		return c.String(`home`)
+	})
+	e.Get("/users", func(c echo.Context) error {
+		return c.String(`users`)
+	})
+	e.RebuildRouter()
+
+	rec := test.Request(echo.GET, "/app/users", e)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `users`, rec.Body.String())
+
+	rec = test.Request(echo.GET, "/app", e)
+	assert.Equal(t, `home`, rec.Body.String())
+
+	rec = test.Request(echo.GET, "/users", e)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	rec = test.Request(echo.GET, "/application/users", e)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestAddPrefixAndRewrite(t *testing.T) {
+	e := echo.New()
+	e.Pre(Rewrite(map[string]string{`/old/*`: `/new/$1`}), AddPrefix(`/v1`))
+	e.Get("/v1/new/:id", func(c echo.Context) error {
+		return c.String(c.Param(`id`))
+	})
+	e.RebuildRouter()
+
+	rec := test.Request(echo.GET, "/old/42", e)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `42`, rec.Body.String())
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/webx-top/echo"
@@ -29,6 +30,10 @@ type (
 
 		//If request gets a  internal limiter error, just skip the limiter and let it go to next middleware
 		SkipRateLimiterInternalError bool
+
+		// MetaKey is the route meta key holding a route specific `Rate`, e.g.
+		// `MustParseRate("10/s")`. Default is "ratelimit".
+		MetaKey string
 	}
 
 	limiter struct {
@@ -66,6 +71,7 @@ var (
 		Prefix:                       "LIMIT",
 		Client:                       nil,
 		SkipRateLimiterInternalError: false,
+		MetaKey:                      "ratelimit",
 	}
 	limiterImp *limiter
 )
@@ -92,6 +98,9 @@ func RateLimiterWithConfig(config RateLimiterConfig) echo.MiddlewareFunc {
 	if config.Duration <= 0 {
 		config.Duration = time.Minute * 1
 	}
+	if config.MetaKey == "" {
+		config.MetaKey = DefaultRateLimiterConfig.MetaKey
+	}
 
 	//If config.Client omit, the limiter is a memory limiter
 	if config.Client == nil {
@@ -117,6 +126,9 @@ func RateLimiterWithConfig(config RateLimiterConfig) echo.MiddlewareFunc {
 			]
 			*/
 			policy := []int{}
+			if rate, ok := c.Route().Meta.Get(config.MetaKey).(Rate); ok {
+				policy = append(policy, rate.Max, int(rate.Duration/time.Millisecond))
+			}
 			result, err := limiterImp.Get(request.URI(), policy...)
 
 			if err != nil {
@@ -142,6 +154,54 @@ func RateLimiterWithConfig(config RateLimiterConfig) echo.MiddlewareFunc {
 	}
 }
 
+// Rate is a route specific limit of `Max` requests per `Duration`.
+type Rate struct {
+	Max      int
+	Duration time.Duration
+}
+
+// ParseRate parses a rate limit spec in the form of `<max>/<period>`, the
+// period is a unit (`s`, `m`, `h` or `d`) or a duration such as `30s`.
+// e.g. `10/s` allows 10 requests per second.
+func ParseRate(spec string) (rate Rate, err error) {
+	parts := strings.SplitN(spec, "/", 2)
+	if len(parts) != 2 {
+		return rate, fmt.Errorf("ratelimiter: invalid rate %q", spec)
+	}
+	rate.Max, err = strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil || rate.Max <= 0 {
+		return Rate{}, fmt.Errorf("ratelimiter: invalid rate %q", spec)
+	}
+	period := strings.TrimSpace(parts[1])
+	switch period {
+	case "s", "sec", "second":
+		rate.Duration = time.Second
+	case "m", "min", "minute":
+		rate.Duration = time.Minute
+	case "h", "hour":
+		rate.Duration = time.Hour
+	case "d", "day":
+		rate.Duration = time.Hour * 24
+	default:
+		rate.Duration, err = time.ParseDuration(period)
+		if err != nil || rate.Duration < time.Millisecond {
+			return Rate{}, fmt.Errorf("ratelimiter: invalid rate %q", spec)
+		}
+	}
+	return
+}
+
+// MustParseRate is like `ParseRate` but panics if the spec is invalid, so that
+// a route meta is validated when the route is registered, e.g.
+// `e.Get("/", h).WithMeta(echo.H{"ratelimit": ratelimiter.MustParseRate("10/s")})`.
+func MustParseRate(spec string) Rate {
+	rate, err := ParseRate(spec)
+	if err != nil {
+		panic(err)
+	}
+	return rate
+}
+
 // get & remove
 
 func (l *limiter) Get(id string, policy ...int) (Result, error) {
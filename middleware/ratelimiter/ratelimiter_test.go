@@ -113,3 +113,50 @@ func TestRateLimiter(t *testing.T) {
 		})
 	})
 }
+
+func TestParseRate(t *testing.T) {
+	rate, err := ParseRate("10/s")
+	assert.NoError(t, err)
+	assert.Equal(t, Rate{Max: 10, Duration: time.Second}, rate)
+
+	rate, err = ParseRate("100/30m")
+	assert.NoError(t, err)
+	assert.Equal(t, Rate{Max: 100, Duration: 30 * time.Minute}, rate)
+
+	for _, spec := range []string{"10", "x/s", "0/s", "10/fortnight"} {
+		_, err = ParseRate(spec)
+		assert.Error(t, err, spec)
+		assert.Panics(t, func() { MustParseRate(spec) }, spec)
+	}
+}
+
+func TestRateLimiterRouteMeta(t *testing.T) {
+	e := echo.New()
+	e.Use(RateLimiterWithConfig(RateLimiterConfig{Max: 5}))
+	handler := func(c echo.Context) error {
+		return c.String("test")
+	}
+	e.Get("/one", handler).WithMeta(echo.H{"ratelimit": MustParseRate("1/m")})
+	e.Get("/two", handler).WithMeta(echo.H{"ratelimit": MustParseRate("2/m")})
+	e.Get("/default", handler)
+	e.RebuildRouter()
+
+	request := func(path string) *httptest.ResponseRecorder {
+		return te.Request(http.MethodGet, path, e)
+	}
+
+	rec := request("/one")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "1", rec.Header().Get("X-Ratelimit-Limit"))
+	assert.Equal(t, http.StatusTooManyRequests, request("/one").Code)
+
+	rec = request("/two")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "2", rec.Header().Get("X-Ratelimit-Limit"))
+	assert.Equal(t, http.StatusOK, request("/two").Code)
+	assert.Equal(t, http.StatusTooManyRequests, request("/two").Code)
+
+	rec = request("/default")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "5", rec.Header().Get("X-Ratelimit-Limit"))
+}
@@ -65,6 +65,7 @@ func New(templateDir string, args ...logger.Logger) driver.Driver {
 		debug:             Debug,
 		fileEvents:        make([]func(string), 0),
 		contentProcessors: make([]func([]byte) []byte, 0),
+		requestFuncs:      make(map[string]struct{}),
 	}
 	if len(args) > 0 {
 		t.logger = args[0]
@@ -124,6 +125,7 @@ type Standard struct {
 	quotedLeft         string
 	quotedRight        string
 	quotedRfirst       string
+	requestFuncs       map[string]struct{} // names of the functions set via `Context.SetFunc`
 }
 
 func (self *Standard) Debug() bool {
@@ -255,16 +257,24 @@ func (self *Standard) InitRegexp() {
 	self.stripTagRegex = regexp.MustCompile(`(?s)` + self.quotedLeft + self.StripTag + self.quotedRight + `(.*?)` + self.quotedLeft + `\/` + self.StripTag + self.quotedRight)
 }
 
+// lock guards the templates and the request-scoped functions, the returned
+// function unlocks them. It does nothing if they are already locked for c,
+// e.g. for `Fetch` called by a template during `Render`.
+func (self *Standard) lock(c echo.Context) (unlock func()) {
+	if c.Get(`webx:render.locked`) != nil {
+		return func() {}
+	}
+	c.Set(`webx:render.locked`, true)
+	self.mutex.Lock()
+	return func() {
+		self.mutex.Unlock()
+		c.Delete(`webx:render.locked`)
+	}
+}
+
 // Render HTML
 func (self *Standard) Render(w io.Writer, tmplName string, values interface{}, c echo.Context) error {
-	if c.Get(`webx:render.locked`) == nil {
-		c.Set(`webx:render.locked`, true)
-		self.mutex.Lock()
-		defer func() {
-			self.mutex.Unlock()
-			c.Delete(`webx:render.locked`)
-		}()
-	}
+	defer self.lock(c)()
 	tmpl, err := self.parse(c, tmplName)
 	if err != nil {
 		return err
@@ -285,7 +295,17 @@ func (self *Standard) parse(c echo.Context, tmplName string) (tmpl *htmlTpl.Temp
 	if funcMap == nil {
 		funcMap = htmlTpl.FuncMap{}
 	}
+	// The cached templates keep the functions of the previous request, so the
+	// request-scoped functions which are not set by this request are replaced.
+	for k := range self.requestFuncs {
+		if _, ok := funcMap[k]; !ok {
+			funcMap[k] = unavailableFunc(k)
+		}
+	}
 	for k, v := range funcs {
+		if _, ok := funcMap[k]; !ok {
+			self.requestFuncs[k] = struct{}{}
+		}
 		funcMap[k] = v
 	}
 	rel, ok := self.CachedRelation[cachedKey]
@@ -409,6 +429,7 @@ func (self *Standard) parse(c echo.Context, tmplName string) (tmpl *htmlTpl.Temp
 }
 
 func (self *Standard) Fetch(tmplName string, data interface{}, c echo.Context) string {
+	defer self.lock(c)()
 	content, _ := self.parse(c, tmplName)
 	return self.execute(content, data)
 }
@@ -633,6 +654,12 @@ func (self *Standard) Close() {
 	}
 }
 
+func unavailableFunc(name string) func(...interface{}) (interface{}, error) {
+	return func(...interface{}) (interface{}, error) {
+		return nil, fmt.Errorf(`function %q is not available in this request`, name)
+	}
+}
+
 func setFunc(tplInf *tplInfo, funcMap htmlTpl.FuncMap) htmlTpl.FuncMap {
 	if funcMap == nil {
 		funcMap = htmlTpl.FuncMap{}
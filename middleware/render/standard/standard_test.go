@@ -0,0 +1,76 @@
+package standard
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/webx-top/echo"
+	test "github.com/webx-top/echo/testing"
+)
+
+func TestRequestFuncs(t *testing.T) {
+	dir, err := ioutil.TempDir(``, `echo-standard`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	ioutil.WriteFile(filepath.Join(dir, `hello.html`), []byte(`{{Greet "webx"}}`), 0644)
+
+	e := echo.New()
+	e.SetRenderer(New(dir))
+	e.Get("/with", func(c echo.Context) error {
+		c.SetFunc(`Greet`, func(name string) string {
+			return `hello ` + name
+		})
+		return c.Render(`hello`, nil)
+	})
+	e.Get("/without", func(c echo.Context) error {
+		return c.Render(`hello`, nil)
+	})
+	e.RebuildRouter()
+
+	rec := test.Request(echo.GET, "/with", e)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `hello webx`, rec.Body.String())
+
+	rec = test.Request(echo.GET, "/without", e)
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.NotContains(t, rec.Body.String(), `hello webx`)
+}
+
+func TestFetchConcurrent(t *testing.T) {
+	dir, err := ioutil.TempDir(``, `echo-standard`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	ioutil.WriteFile(filepath.Join(dir, `hello.html`), []byte(`{{Greet "webx"}}`), 0644)
+
+	e := echo.New()
+	renderer := New(dir)
+	results := make(chan string, 10)
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req := test.NewStdRequest(echo.GET, `/`)
+			c := e.NewContext(test.WrapRequest(req), test.WrapResponse(req, test.NewStdResponse()))
+			c.SetFunc(`Greet`, func(name string) string {
+				return `hello ` + name
+			})
+			results <- renderer.Fetch(`hello`, nil, c)
+		}()
+	}
+	wg.Wait()
+	close(results)
+	for result := range results {
+		assert.Equal(t, `hello webx`, result)
+	}
+}
@@ -0,0 +1,110 @@
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"time"
+
+	"github.com/webx-top/echo"
+	"github.com/webx-top/echo/middleware/random"
+)
+
+type contextKey struct{}
+
+// ContextKey is the key of the request ID in `Context.StdContext()`, so HTTP
+// and gRPC clients called with it can propagate the ID downstream.
+var ContextKey = contextKey{}
+
+// Config defines the config for RequestID middleware.
+type Config struct {
+	// Skipper defines a function to skip middleware.
+	Skipper echo.Skipper `json:"-"`
+
+	// Generator generates the ID of a request which has none,
+	// default is a random 32 characters alphanumeric string.
+	Generator func() string `json:"-"`
+
+	// Header is the request and response header holding the ID,
+	// default is "X-Request-ID".
+	Header string `json:"header"`
+}
+
+// DefaultConfig is the default RequestID middleware config.
+var DefaultConfig = Config{
+	Skipper: echo.DefaultSkipper,
+	Generator: func() string {
+		return random.String(32)
+	},
+	Header: echo.HeaderXRequestID,
+}
+
+// RequestID returns a middleware which reuses the ID of the request header or
+// generates one, then sets it to the response header and `Context.StdContext()`.
+func RequestID() echo.MiddlewareFuncd {
+	return RequestIDWithConfig(DefaultConfig)
+}
+
+// RequestIDWithConfig returns a RequestID middleware from config.
+// See: `RequestID()`.
+func RequestIDWithConfig(config Config) echo.MiddlewareFuncd {
+	if config.Skipper == nil {
+		config.Skipper = DefaultConfig.Skipper
+	}
+	if config.Generator == nil {
+		config.Generator = DefaultConfig.Generator
+	}
+	if len(config.Header) == 0 {
+		config.Header = DefaultConfig.Header
+	}
+	return func(next echo.Handler) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.Skipper(c) {
+				return next.Handle(c)
+			}
+			id := c.Header(config.Header)
+			if len(id) == 0 {
+				id = config.Generator()
+			}
+			c.Response().Header().Set(config.Header, id)
+			c.SetStdContext(context.WithValue(c.StdContext(), ContextKey, id))
+			return next.Handle(c)
+		}
+	}
+}
+
+// FromContext returns the request ID stored in ctx, or "" if there is none.
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(ContextKey).(string)
+	return id
+}
+
+// Get returns the ID of the current request.
+func Get(c echo.Context) string {
+	return FromContext(c.StdContext())
+}
+
+// UUIDv7 generates a time-ordered UUID version 7, it can be used as `Config.Generator`.
+func UUIDv7() string {
+	var u [16]byte
+	if _, err := rand.Read(u[6:]); err != nil {
+		panic(err)
+	}
+	var ts [8]byte
+	binary.BigEndian.PutUint64(ts[:], uint64(time.Now().UnixNano()/int64(time.Millisecond)))
+	copy(u[:6], ts[2:])
+	u[6] = (u[6] & 0x0f) | 0x70 // version 7
+	u[8] = (u[8] & 0x3f) | 0x80 // variant RFC 4122
+	var b [36]byte
+	hex.Encode(b[0:8], u[0:4])
+	b[8] = '-'
+	hex.Encode(b[9:13], u[4:6])
+	b[13] = '-'
+	hex.Encode(b[14:18], u[6:8])
+	b[18] = '-'
+	hex.Encode(b[19:23], u[8:10])
+	b[23] = '-'
+	hex.Encode(b[24:], u[10:])
+	return string(b[:])
+}
@@ -0,0 +1,46 @@
+package requestid
+
+import (
+	"net/http"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/webx-top/echo"
+	test "github.com/webx-top/echo/testing"
+)
+
+func TestRequestID(t *testing.T) {
+	e := echo.New()
+	e.Use(RequestID())
+	e.Get("/", func(c echo.Context) error {
+		return c.String(FromContext(c.StdContext()))
+	})
+	e.RebuildRouter()
+
+	rec := test.Request(echo.GET, "/", e)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Len(t, rec.Body.String(), 32)
+	assert.Equal(t, rec.Body.String(), rec.Header().Get(echo.HeaderXRequestID))
+
+	rec = test.Request(echo.GET, "/", e, func(req *http.Request) {
+		req.Header.Set(echo.HeaderXRequestID, `upstream-id`)
+	})
+	assert.Equal(t, `upstream-id`, rec.Body.String())
+	assert.Equal(t, `upstream-id`, rec.Header().Get(echo.HeaderXRequestID))
+}
+
+func TestRequestIDGenerator(t *testing.T) {
+	e := echo.New()
+	e.Use(RequestIDWithConfig(Config{Generator: UUIDv7, Header: `X-Trace-ID`}))
+	e.Get("/", func(c echo.Context) error {
+		return c.String(Get(c))
+	})
+	e.RebuildRouter()
+
+	rec := test.Request(echo.GET, "/", e)
+	assert.Regexp(t, regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`), rec.Body.String())
+	assert.Equal(t, rec.Body.String(), rec.Header().Get(`X-Trace-ID`))
+	assert.Empty(t, rec.Header().Get(echo.HeaderXRequestID))
+}
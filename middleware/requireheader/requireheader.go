@@ -0,0 +1,77 @@
+package requireheader
+
+import (
+	"net/http"
+
+	"github.com/webx-top/echo"
+)
+
+type Config struct {
+	// Skipper defines a function to skip middleware.
+	Skipper echo.Skipper `json:"-"`
+
+	// Header is the name of the required request header, e.g. `X-API-Version`.
+	Header string `json:"header"`
+
+	// Allowed lists the allowed values of the header, any value is allowed
+	// when it is empty.
+	Allowed []string `json:"allowed"`
+
+	// DisallowedCode is the status code used when the value is not allowed.
+	// Optional. Default value http.StatusBadRequest.
+	DisallowedCode int `json:"disallowedCode"`
+}
+
+var (
+	// DefaultConfig is the default RequireHeader middleware config.
+	DefaultConfig = Config{
+		Skipper:        echo.DefaultSkipper,
+		DisallowedCode: http.StatusBadRequest,
+	}
+)
+
+// Require responds 400 Bad Request to the requests without the header or
+// whose header value is not one of allowed.
+// Usage `Echo#Use(requireheader.Require("X-API-Version", "1", "2"))`
+func Require(name string, allowed ...string) echo.MiddlewareFuncd {
+	config := DefaultConfig
+	config.Header = name
+	config.Allowed = allowed
+	return RequireWithConfig(config)
+}
+
+// RequireWithConfig returns a RequireHeader middleware with config.
+func RequireWithConfig(config Config) echo.MiddlewareFuncd {
+	// Defaults
+	if config.Skipper == nil {
+		config.Skipper = DefaultConfig.Skipper
+	}
+	if config.DisallowedCode == 0 {
+		config.DisallowedCode = DefaultConfig.DisallowedCode
+	}
+	if len(config.Header) == 0 {
+		panic(`requireheader middleware requires a header name`)
+	}
+	allowed := make(map[string]struct{}, len(config.Allowed))
+	for _, value := range config.Allowed {
+		allowed[value] = struct{}{}
+	}
+
+	return func(next echo.Handler) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.Skipper(c) {
+				return next.Handle(c)
+			}
+			value := c.Request().Header().Get(config.Header)
+			if len(value) == 0 {
+				return echo.NewHTTPError(http.StatusBadRequest, `missing header `+config.Header)
+			}
+			if len(allowed) > 0 {
+				if _, ok := allowed[value]; !ok {
+					return echo.NewHTTPError(config.DisallowedCode, `unsupported `+config.Header+`: `+value)
+				}
+			}
+			return next.Handle(c)
+		}
+	}
+}
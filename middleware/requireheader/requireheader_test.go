@@ -0,0 +1,61 @@
+package requireheader
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/webx-top/echo"
+	test "github.com/webx-top/echo/testing"
+)
+
+func newEcho(m echo.MiddlewareFuncd) *echo.Echo {
+	e := echo.New()
+	e.Use(m)
+	e.Get(`/`, func(c echo.Context) error {
+		return c.String(`OK`)
+	})
+	e.RebuildRouter()
+	return e
+}
+
+func withVersion(version string) func(*http.Request) {
+	return func(req *http.Request) {
+		if len(version) > 0 {
+			req.Header.Set(`X-API-Version`, version)
+		}
+	}
+}
+
+func TestRequire(t *testing.T) {
+	e := newEcho(Require(`X-API-Version`, `1`, `2`))
+
+	rec := test.Request(echo.GET, `/`, e, withVersion(``))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, `missing header X-API-Version`, rec.Body.String())
+
+	rec = test.Request(echo.GET, `/`, e, withVersion(`3`))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, `unsupported X-API-Version: 3`, rec.Body.String())
+
+	rec = test.Request(echo.GET, `/`, e, withVersion(`2`))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestRequireWithConfig(t *testing.T) {
+	e := newEcho(RequireWithConfig(Config{
+		Header:         `X-API-Version`,
+		Allowed:        []string{`1`},
+		DisallowedCode: http.StatusConflict,
+	}))
+	rec := test.Request(echo.GET, `/`, e, withVersion(`2`))
+	assert.Equal(t, http.StatusConflict, rec.Code)
+
+	rec = test.Request(echo.GET, `/`, e, withVersion(``))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	// any value is allowed without Allowed
+	e = newEcho(Require(`X-API-Version`))
+	rec = test.Request(echo.GET, `/`, e, withVersion(`anything`))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/webx-top/echo"
 )
@@ -51,11 +52,16 @@ type (
 		// security against cross-site scripting (XSS), clickjacking and other code
 		// injection attacks resulting from execution of malicious content in the
 		// trusted web page context.
+		// The placeholder "{nonce}" is replaced with `Context.CSPNonce()`,
+		// e.g. "script-src 'nonce-{nonce}'".
 		// Optional. Default value "".
 		ContentSecurityPolicy string `json:"content_security_policy"`
 	}
 )
 
+// CSPNoncePlaceholder is replaced with the request nonce in `SecureConfig.ContentSecurityPolicy`.
+const CSPNoncePlaceholder = "{nonce}"
+
 var (
 	// DefaultSecureConfig is the default Secure middleware config.
 	DefaultSecureConfig = SecureConfig{
@@ -108,7 +114,11 @@ func SecureWithConfig(config SecureConfig) echo.MiddlewareFuncd {
 				hdr.Set(echo.HeaderStrictTransportSecurity, fmt.Sprintf("max-age=%d%s", config.HSTSMaxAge, subdomains))
 			}
 			if config.ContentSecurityPolicy != "" {
-				hdr.Set(echo.HeaderContentSecurityPolicy, config.ContentSecurityPolicy)
+				csp := config.ContentSecurityPolicy
+				if strings.Contains(csp, CSPNoncePlaceholder) {
+					csp = strings.Replace(csp, CSPNoncePlaceholder, c.CSPNonce(), -1)
+				}
+				hdr.Set(echo.HeaderContentSecurityPolicy, csp)
 			}
 			return next.Handle(c)
 		}
@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/webx-top/echo"
+	test "github.com/webx-top/echo/testing"
+)
+
+func TestSecureCSPNonce(t *testing.T) {
+	e := echo.New()
+	e.Use(SecureWithConfig(SecureConfig{
+		ContentSecurityPolicy: "script-src 'nonce-{nonce}'",
+	}))
+	e.Get("/", func(c echo.Context) error {
+		return c.String(c.CSPNonce())
+	})
+	e.RebuildRouter()
+
+	rec := test.Request(echo.GET, "/", e)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NotEmpty(t, rec.Body.String())
+	assert.Equal(t, "script-src 'nonce-"+rec.Body.String()+"'", rec.Header().Get(echo.HeaderContentSecurityPolicy))
+
+	rec2 := test.Request(echo.GET, "/", e)
+	assert.NotEqual(t, rec.Body.String(), rec2.Body.String())
+}
@@ -0,0 +1,129 @@
+package singleflight
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/webx-top/echo"
+)
+
+type Config struct {
+	// Skipper defines a function to skip middleware.
+	// Optional. Default value skips the requests other than GET and HEAD and
+	// the ones with credentials, whose responses may be personalized.
+	Skipper echo.Skipper `json:"-"`
+
+	// KeyFunc returns the key by which concurrent identical requests are collapsed.
+	// Optional. Default value DefaultKeyFunc.
+	KeyFunc func(echo.Context) string `json:"-"`
+}
+
+type call struct {
+	wg     sync.WaitGroup
+	status int
+	header http.Header
+	body   []byte
+	err    error
+}
+
+var (
+	// DefaultConfig is the default Singleflight middleware config.
+	DefaultConfig = Config{
+		Skipper: func(c echo.Context) bool {
+			req := c.Request()
+			if method := req.Method(); method != echo.GET && method != echo.HEAD {
+				return true
+			}
+			header := req.Header()
+			return len(header.Get(echo.HeaderCookie)) > 0 || len(header.Get(echo.HeaderAuthorization)) > 0
+		},
+		KeyFunc: DefaultKeyFunc,
+	}
+)
+
+// testHookWait is called when a request starts waiting for the response of
+// an identical one.
+var testHookWait = func() {}
+
+// DefaultKeyFunc collapses the requests with the same method, host and URL.
+func DefaultKeyFunc(c echo.Context) string {
+	req := c.Request()
+	return req.Method() + ` ` + req.Host() + req.URL().String()
+}
+
+// Singleflight collapses concurrent identical requests into one handler
+// execution and serves its buffered response to all the waiting requests.
+// An error returned by the handler is propagated to all of them. The
+// `Set-Cookie` header is never replayed to the waiting requests.
+func Singleflight(keyFunc func(echo.Context) string) echo.MiddlewareFuncd {
+	config := DefaultConfig
+	config.KeyFunc = keyFunc
+	return SingleflightWithConfig(config)
+}
+
+// SingleflightWithConfig returns a Singleflight middleware with config.
+func SingleflightWithConfig(config Config) echo.MiddlewareFuncd {
+	// Defaults
+	if config.Skipper == nil {
+		config.Skipper = DefaultConfig.Skipper
+	}
+	if config.KeyFunc == nil {
+		config.KeyFunc = DefaultConfig.KeyFunc
+	}
+	var (
+		mu    sync.Mutex
+		calls = map[string]*call{}
+	)
+	return func(next echo.Handler) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.Skipper(c) {
+				return next.Handle(c)
+			}
+			key := config.KeyFunc(c)
+			mu.Lock()
+			if cl, ok := calls[key]; ok {
+				mu.Unlock()
+				testHookWait()
+				cl.wg.Wait()
+				if cl.err != nil {
+					return cl.err
+				}
+				header := c.Response().Header()
+				for name, values := range cl.header {
+					if name == echo.HeaderSetCookie {
+						continue
+					}
+					header.Del(name)
+					for _, value := range values {
+						header.Add(name, value)
+					}
+				}
+				return c.Blob(cl.body, cl.status)
+			}
+			cl := &call{err: echo.NewHTTPError(http.StatusInternalServerError)}
+			cl.wg.Add(1)
+			calls[key] = cl
+			mu.Unlock()
+			defer func() {
+				mu.Lock()
+				delete(calls, key)
+				mu.Unlock()
+				cl.wg.Done()
+			}()
+
+			res := c.Response()
+			res.KeepBody(true)
+			err := next.Handle(c)
+			if err == nil {
+				cl.status = res.Status()
+				if cl.status == 0 {
+					cl.status = http.StatusOK
+				}
+				cl.header = res.Header().Std().Clone()
+				cl.body = res.Body()
+			}
+			cl.err = err
+			return err
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package singleflight
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/webx-top/echo"
+	test "github.com/webx-top/echo/testing"
+)
+
+// concurrentRequests sends n identical requests, the first one is held in the
+// handler until the n-1 others wait for its response.
+func concurrentRequests(e *echo.Echo, path string, n int, entered, release chan struct{}) []*httptest.ResponseRecorder {
+	waiting := make(chan struct{}, n)
+	testHookWait = func() {
+		waiting <- struct{}{}
+	}
+	defer func() {
+		testHookWait = func() {}
+	}()
+	recs := make([]*httptest.ResponseRecorder, n)
+	wg := sync.WaitGroup{}
+	send := func(i int) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			recs[i] = test.Request(echo.GET, path, e)
+		}()
+	}
+	send(0)
+	<-entered
+	for i := 1; i < n; i++ {
+		send(i)
+	}
+	for i := 1; i < n; i++ {
+		<-waiting
+	}
+	close(release)
+	wg.Wait()
+	return recs
+}
+
+func TestSingleflight(t *testing.T) {
+	e := echo.New()
+	e.Use(Singleflight(nil))
+	var calls int32
+	entered := make(chan struct{}, 1)
+	release := make(chan struct{})
+	e.Get("/report", func(c echo.Context) error {
+		atomic.AddInt32(&calls, 1)
+		entered <- struct{}{}
+		<-release
+		c.Response().Header().Set(`X-Report`, `1`)
+		c.SetCookie(`session`, `leader`)
+		return c.String(`report`)
+	})
+	e.Get("/fail", func(c echo.Context) error {
+		atomic.AddInt32(&calls, 1)
+		entered <- struct{}{}
+		<-release
+		return echo.NewHTTPError(http.StatusBadGateway)
+	})
+	e.RebuildRouter()
+
+	recs := concurrentRequests(e, "/report", 10, entered, release)
+	for _, rec := range recs {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, `report`, rec.Body.String())
+		assert.Equal(t, `1`, rec.Header().Get(`X-Report`))
+	}
+	assert.NotEmpty(t, recs[0].Header().Get(echo.HeaderSetCookie))
+	for _, rec := range recs[1:] {
+		assert.Empty(t, rec.Header().Get(echo.HeaderSetCookie))
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	atomic.StoreInt32(&calls, 0)
+	release = make(chan struct{})
+	for _, rec := range concurrentRequests(e, "/fail", 10, entered, release) {
+		assert.Equal(t, http.StatusBadGateway, rec.Code)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	// Sequential requests are not collapsed.
+	atomic.StoreInt32(&calls, 0)
+	release = make(chan struct{})
+	close(release)
+	test.Request(echo.GET, "/report", e)
+	<-entered
+	test.Request(echo.GET, "/report", e)
+	<-entered
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestSingleflightSkipsCredentials(t *testing.T) {
+	e := echo.New()
+	e.Use(Singleflight(nil))
+	entered := make(chan struct{}, 2)
+	release := make(chan struct{})
+	e.Get("/me", func(c echo.Context) error {
+		entered <- struct{}{}
+		<-release
+		return c.String(c.Request().Header().Get(echo.HeaderAuthorization))
+	})
+	e.RebuildRouter()
+
+	recs := make([]*httptest.ResponseRecorder, 2)
+	wg := sync.WaitGroup{}
+	for i, token := range []string{`Bearer alice`, `Bearer bob`} {
+		wg.Add(1)
+		go func(i int, token string) {
+			defer wg.Done()
+			recs[i] = test.Request(echo.GET, "/me", e, func(req *http.Request) {
+				req.Header.Set(echo.HeaderAuthorization, token)
+			})
+		}(i, token)
+	}
+	// Both requests run the handler concurrently.
+	<-entered
+	<-entered
+	close(release)
+	wg.Wait()
+	assert.Equal(t, `Bearer alice`, recs[0].Body.String())
+	assert.Equal(t, `Bearer bob`, recs[1].Body.String())
+}
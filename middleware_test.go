@@ -0,0 +1,178 @@
+package echo_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/webx-top/echo"
+	test "github.com/webx-top/echo/testing"
+)
+
+func TestMethodFilter(t *testing.T) {
+	e := New()
+	var called int
+	g := e.Group("/api", MethodFilter(func(h Handler) HandlerFunc {
+		return func(c Context) error {
+			called++
+			c.Response().Header().Set(`X-Filtered`, `1`)
+			return h.Handle(c)
+		}
+	}, POST, PUT, PATCH, DELETE))
+	g.Match([]string{GET, POST}, "/items", func(c Context) error {
+		return c.String(c.Request().Method())
+	})
+	e.RebuildRouter()
+
+	rec := test.Request(POST, "/api/items", e)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `1`, rec.Header().Get(`X-Filtered`))
+	assert.Equal(t, 1, called)
+
+	rec = test.Request(GET, "/api/items", e)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, GET, rec.Body.String())
+	assert.Empty(t, rec.Header().Get(`X-Filtered`))
+	assert.Equal(t, 1, called)
+}
+
+func TestSkip(t *testing.T) {
+	e := New()
+	e.Use(Skip(func(h Handler) HandlerFunc {
+		return func(c Context) error {
+			return c.NoContent(http.StatusUnauthorized)
+		}
+	}, func(c Context) bool {
+		return c.Path() == "/login"
+	}))
+	e.Get("/login", func(c Context) error {
+		return c.String(`login`)
+	})
+	e.Get("/admin", func(c Context) error {
+		return c.String(`admin`)
+	})
+	e.RebuildRouter()
+
+	rec := test.Request(GET, "/login", e)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `login`, rec.Body.String())
+
+	rec = test.Request(GET, "/admin", e)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestEchoUseNamed(t *testing.T) {
+	e := New()
+	var calls int
+	e.UseNamed(`counter`, func(h Handler) HandlerFunc {
+		return func(c Context) error {
+			calls++
+			return h.Handle(c)
+		}
+	})
+	e.Get("/", func(c Context) error {
+		return c.String(`OK`)
+	})
+	e.RebuildRouter()
+
+	test.Request(GET, "/", e)
+	assert.Equal(t, 1, calls)
+
+	e.ClearNamed(`counter`)
+	rec := test.Request(GET, "/", e)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, 1, calls)
+}
+
+func TestEchoSetGroupDefaults(t *testing.T) {
+	e := New()
+	var calls int
+	e.SetGroupDefaults(func(h Handler) HandlerFunc {
+		return func(c Context) error {
+			calls++
+			return h.Handle(c)
+		}
+	})
+	e.Get("/", func(c Context) error {
+		return c.String(`root`)
+	})
+	g := e.Group("/api")
+	g.Get("/ping", func(c Context) error {
+		return c.String(`pong`)
+	})
+	g.Group("/v1").Get("/ping", func(c Context) error {
+		return c.String(`pong v1`)
+	})
+	e.RebuildRouter()
+
+	rec := test.Request(GET, "/", e)
+	assert.Equal(t, `root`, rec.Body.String())
+	assert.Equal(t, 0, calls)
+
+	rec = test.Request(GET, "/api/ping", e)
+	assert.Equal(t, `pong`, rec.Body.String())
+	assert.Equal(t, 1, calls)
+
+	rec = test.Request(GET, "/api/v1/ping", e)
+	assert.Equal(t, `pong v1`, rec.Body.String())
+	assert.Equal(t, 2, calls)
+}
+
+func TestEchoMiddlewareChain(t *testing.T) {
+	e := New()
+	var order []string
+	pre := func(h Handler) HandlerFunc {
+		return func(c Context) error {
+			order = append(order, `pre`)
+			return h.Handle(c)
+		}
+	}
+	global := func(h Handler) HandlerFunc {
+		return func(c Context) error {
+			order = append(order, `global`)
+			return h.Handle(c)
+		}
+	}
+	group := func(h Handler) HandlerFunc {
+		return func(c Context) error {
+			order = append(order, `group`)
+			return h.Handle(c)
+		}
+	}
+	route := func(h Handler) HandlerFunc {
+		return func(c Context) error {
+			order = append(order, `route`)
+			return h.Handle(c)
+		}
+	}
+	e.Pre(pre)
+	e.Use(global)
+	e.UseNamed(`named`, func(h Handler) HandlerFunc {
+		return func(c Context) error {
+			order = append(order, `named`)
+			return h.Handle(c)
+		}
+	})
+	e.Group("/api", group).Get("/users/:id", func(c Context) error {
+		return c.String(`OK`)
+	}, route)
+	e.RebuildRouter()
+
+	labels := map[string]string{
+		HandlerName(pre):    `pre`,
+		HandlerName(global): `global`,
+		`named`:             `named`,
+		HandlerName(group):  `group`,
+		HandlerName(route):  `route`,
+	}
+	var chain []string
+	for _, name := range e.MiddlewareChain(GET, "/api/users/1") {
+		chain = append(chain, labels[name])
+	}
+	assert.Nil(t, e.MiddlewareChain(GET, "/missing"))
+
+	test.Request(GET, "/api/users/1", e)
+	assert.Equal(t, []string{`pre`, `global`, `named`, `group`, `route`}, order)
+	assert.Equal(t, order, chain)
+}
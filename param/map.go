@@ -4,7 +4,10 @@ import (
 	"encoding/xml"
 	"fmt"
 	"html/template"
+	"strings"
 	"time"
+
+	"github.com/webx-top/echo/encoding/json"
 )
 
 var (
@@ -218,6 +221,95 @@ func (s Store) Clone() Store {
 	return r
 }
 
+// Merge copies every key of source into s, overwriting existing keys.
+// Unlike DeepMerge, nested Store values are replaced rather than merged.
+func (s Store) Merge(source Store) Store {
+	for k, value := range source {
+		s[k] = value
+	}
+	return s
+}
+
+// GetPath returns the value at the dotted path (e.g. `user.profile.name`),
+// descending into nested Store and map[string]interface{} values.
+func (s Store) GetPath(path string, defaults ...interface{}) interface{} {
+	var value interface{} = s
+	for _, key := range strings.Split(path, `.`) {
+		var ok bool
+		switch m := value.(type) {
+		case Store:
+			value, ok = m[key]
+		case map[string]interface{}:
+			value, ok = m[key]
+		}
+		if !ok {
+			value = nil
+			break
+		}
+	}
+	if value == nil && len(defaults) > 0 {
+		if fallback, ok := defaults[0].(func() interface{}); ok {
+			return fallback()
+		}
+		return defaults[0]
+	}
+	return value
+}
+
+// MarshalJSON allows type Store to be used with json.Marshal.
+// The nested stores are encoded in the same pass as plain maps, whose keys
+// the JSON encoder writes in sorted order.
+func (s Store) MarshalJSON() ([]byte, error) {
+	if s == nil {
+		return []byte(`null`), nil
+	}
+	return json.Marshal(plainMap(s))
+}
+
+// plainMap converts the stores nested in the map into plain maps, so that
+// `Store.MarshalJSON` is not called again for each of them.
+func plainMap(m map[string]interface{}) map[string]interface{} {
+	r := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		r[k] = plainValue(v)
+	}
+	return r
+}
+
+func plainValue(v interface{}) interface{} {
+	switch t := v.(type) {
+	case Store:
+		if t == nil {
+			return nil
+		}
+		return plainMap(t)
+	case map[string]interface{}:
+		if t == nil {
+			return nil
+		}
+		return plainMap(t)
+	case []Store:
+		if t == nil {
+			return nil
+		}
+		r := make([]interface{}, len(t))
+		for i, s := range t {
+			r[i] = plainValue(s)
+		}
+		return r
+	case []interface{}:
+		if t == nil {
+			return nil
+		}
+		r := make([]interface{}, len(t))
+		for i, e := range t {
+			r[i] = plainValue(e)
+		}
+		return r
+	}
+	return v
+}
+
 func (s Store) Transform(transfers map[string]Transfer) Store {
 	rmap := Store{}
 	for key, transfer := range transfers {
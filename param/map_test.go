@@ -0,0 +1,47 @@
+package param
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStoreMerge(t *testing.T) {
+	s := Store{`a`: 1, `b`: Store{`c`: 2}}
+	s.Merge(Store{`b`: Store{`d`: 3}, `e`: 4})
+	assert.Equal(t, Store{`a`: 1, `b`: Store{`d`: 3}, `e`: 4}, s)
+}
+
+func TestStoreCloneIsolation(t *testing.T) {
+	s := Store{`a`: 1, `b`: Store{`c`: 2}}
+	r := s.Clone()
+	r.Set(`a`, 10)
+	r.Store(`b`).Set(`c`, 20)
+	assert.Equal(t, 1, s.Int(`a`))
+	assert.Equal(t, 2, s.Store(`b`).Int(`c`))
+}
+
+func TestStoreGetPath(t *testing.T) {
+	s := Store{
+		`user`: Store{
+			`profile`: map[string]interface{}{`name`: `webx`},
+		},
+		`a.b`: 1,
+	}
+	assert.Equal(t, `webx`, s.GetPath(`user.profile.name`))
+	assert.Nil(t, s.GetPath(`user.profile.age`))
+	assert.Nil(t, s.GetPath(`user.profile.name.first`))
+	assert.Equal(t, 18, s.GetPath(`user.age`, 18))
+	assert.Equal(t, 1, s.Get(`a.b`))
+}
+
+func TestStoreMarshalJSON(t *testing.T) {
+	s := Store{`b`: 1, `a`: Store{`d`: true, `c`: nil}, `c`: []Store{{`z`: 1, `y`: 2}}}
+	b, err := json.Marshal(s)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"a":{"c":null,"d":true},"b":1,"c":[{"y":2,"z":1}]}`, string(b))
+	b, err = json.Marshal(Store(nil))
+	assert.NoError(t, err)
+	assert.Equal(t, `null`, string(b))
+}
@@ -68,6 +68,9 @@ type ICore interface {
 
 type IRouter interface {
 	SetName(string) IRouter
+	WithMeta(H) IRouter
+	SetPriority(int) IRouter
+	Alias(...string) IRouter
 }
 
 type Closer interface {
@@ -10,13 +10,93 @@ import (
 
 var defaultRoute = &Route{}
 
+// RouteConflictPolicy defines how `Echo.RebuildRouter` handles the routes
+// registered more than once with the same host, method and path. The paths
+// differing only by the param names (e.g. `/users/:id` and `/users/:uid`) are
+// the same path.
+type RouteConflictPolicy int
+
+const (
+	// RouteConflictIgnore keeps all the routes, the last one is dispatched.
+	RouteConflictIgnore RouteConflictPolicy = iota
+	// RouteConflictWarn is the same as RouteConflictIgnore, but logs a warning.
+	RouteConflictWarn
+	// RouteConflictLastWins removes the routes registered earlier.
+	RouteConflictLastWins
+	// RouteConflictPanic panics with a *RouteConflictError.
+	RouteConflictPanic
+)
+
+// RouteConflictError describes a route registered more than once.
+type RouteConflictError struct {
+	Route *Route
+}
+
+func (e *RouteConflictError) Error() string {
+	return fmt.Sprintf(`echo: route %s %s%s is registered more than once`, e.Route.Method, e.Route.Host, e.Route.Path)
+}
+
+// resolveRouteConflicts returns the routes without the conflicts according to
+// the policy.
+func resolveRouteConflicts(e *Echo, routes []*Route, policy RouteConflictPolicy) []*Route {
+	last := make(map[string]int, len(routes))
+	for i, r := range routes {
+		key := routeConflictKey(r)
+		if _, ok := last[key]; ok {
+			reportRouteConflict(e, r, policy)
+		}
+		last[key] = i
+	}
+	if policy != RouteConflictLastWins || len(last) == len(routes) {
+		return routes
+	}
+	result := make([]*Route, 0, len(last))
+	for i, r := range routes {
+		if last[routeConflictKey(r)] == i {
+			result = append(result, r)
+		}
+	}
+	return result
+}
+
+// reportRouteConflict logs or panics on the route registered more than once,
+// according to the policy.
+func reportRouteConflict(e *Echo, r *Route, policy RouteConflictPolicy) {
+	switch policy {
+	case RouteConflictWarn:
+		e.logger.Warn((&RouteConflictError{Route: r}).Error())
+	case RouteConflictPanic:
+		panic(&RouteConflictError{Route: r})
+	}
+}
+
+// routeConflictKey returns the host, method and path of the route, the param
+// names are removed from the path as the router does.
+func routeConflictKey(r *Route) string {
+	path := r.Path
+	for i := strings.IndexByte(path, ':'); i >= 0 && i < len(path); {
+		j := i + 1
+		for j < len(path) && path[j] != '/' {
+			j++
+		}
+		path = path[:i+1] + path[j:]
+		next := strings.IndexByte(path[i+1:], ':')
+		if next < 0 {
+			break
+		}
+		i += 1 + next
+	}
+	return r.Host + ` ` + r.Method + ` ` + path
+}
+
 type (
 	Router struct {
-		tree   *node
-		static map[string]*methodHandler
-		routes []*Route
-		nroute map[string][]int
-		echo   *Echo
+		tree        *node
+		static      map[string]*methodHandler
+		routes      []*Route
+		nroute      map[string][]int
+		echo        *Echo
+		prioritized bool // has routes with a non-zero priority
 	}
 
 	meta struct {
@@ -34,6 +114,9 @@ type (
 		Params     []string //param names
 		Prefix     string
 		Meta       H
+		Priority   int           //higher priority routes are matched first
+		Aliases    []string      //additional paths resolving to the route
+		meta       H             //WithMeta
 		handler    interface{}   //原始handler
 		middleware []interface{} //中间件
 	}
@@ -41,8 +124,9 @@ type (
 	Routes []*Route
 
 	endpoint struct {
-		handler Handler
-		rid     int //routes index
+		handler  Handler
+		rid      int //routes index
+		priority int
 	}
 
 	node struct {
@@ -67,6 +151,7 @@ type (
 		post    *endpoint
 		put     *endpoint
 		trace   *endpoint
+		custom  map[string]*endpoint // non-standard methods, e.g. PROPFIND
 	}
 )
 
@@ -88,6 +173,73 @@ func (r *Route) SetName(name string) IRouter {
 	return r
 }
 
+func (r Routes) SetPriority(priority int) IRouter {
+	for _, route := range r {
+		route.SetPriority(priority)
+	}
+	return r
+}
+
+// SetPriority sets the matching priority of the route, the default is 0.
+// The routes with a higher priority are matched before the others regardless
+// of the static > param > any order, e.g. `/users/:id` with priority 1 matches
+// `/users/new` even if a static route is registered for it.
+func (r *Route) SetPriority(priority int) IRouter {
+	r.Priority = priority
+	return r
+}
+
+func (r Routes) Alias(paths ...string) IRouter {
+	for _, route := range r {
+		route.Alias(paths...)
+	}
+	return r
+}
+
+// Alias registers additional full paths resolving to the same route, e.g. the
+// old `/v1/users` of `/v2/users`. `Echo.URI` still builds the canonical path.
+func (r *Route) Alias(paths ...string) IRouter {
+	r.Aliases = append(r.Aliases, paths...)
+	return r
+}
+
+func (r Routes) WithMeta(meta H) IRouter {
+	for _, route := range r {
+		route.WithMeta(meta)
+	}
+	return r
+}
+
+// WithMeta attaches meta information to the route. It is merged over the
+// meta of a `MetaHandler` when the router is built.
+func (r *Route) WithMeta(meta H) IRouter {
+	if r.meta == nil {
+		r.meta = H{}
+	}
+	for key, value := range meta {
+		r.meta[key] = value
+	}
+	return r
+}
+
+// RawHandler returns the handler as it was registered, before it was converted
+// to a Handler and wrapped by the route middleware into the `Handler` field.
+func (r *Route) RawHandler() interface{} {
+	return r.handler
+}
+
+// HandlerName returns the name of the registered handler, which is unchanged
+// by `SetName`.
+func (r *Route) HandlerName() string {
+	if r.handler == nil {
+		return ``
+	}
+	if hn, ok := r.handler.(Name); ok {
+		return hn.Name()
+	}
+	return HandlerName(r.handler)
+}
+
 func (r *Route) IsZero() bool {
 	return r.Handler == nil
 }
@@ -101,6 +253,7 @@ func (r *Route) MakeURI(params ...interface{}) (uri string) {
 			for _, name := range r.Params {
 				tag := `:` + name
 				v := val.Get(name)
+				uri, tag = r.trimOptional(uri, tag, v)
 				uri = strings.Replace(uri, tag+`/`, v+`/`, -1)
 				if strings.HasSuffix(uri, tag) {
 					uri = strings.TrimSuffix(uri, tag) + v
@@ -119,6 +272,7 @@ func (r *Route) MakeURI(params ...interface{}) (uri string) {
 				if y {
 					delete(val, name)
 				}
+				uri, tag = r.trimOptional(uri, tag, v)
 				uri = strings.Replace(uri, tag+`/`, v+`/`, -1)
 				if strings.HasSuffix(uri, tag) {
 					uri = strings.TrimSuffix(uri, tag) + v
@@ -135,16 +289,52 @@ func (r *Route) MakeURI(params ...interface{}) (uri string) {
 				sep = `&`
 			}
 		case []interface{}:
-			uri = fmt.Sprintf(r.Format, val...)
+			uri = fmt.Sprintf(r.format(len(val)), val...)
 		default:
-			uri = fmt.Sprintf(r.Format, val)
+			uri = fmt.Sprintf(r.format(1), val)
 		}
 	} else {
-		uri = fmt.Sprintf(r.Format, params...)
+		uri = fmt.Sprintf(r.format(length), params...)
 	}
 	return
 }
 
+// trimOptional removes the segment of the optional param from uri when its
+// value is empty. It returns the tag to be replaced otherwise.
+func (r *Route) trimOptional(uri string, tag string, value string) (string, string) {
+	if !strings.Contains(uri, tag+`?`) {
+		return uri, tag
+	}
+	if len(value) == 0 {
+		uri = strings.Replace(uri, `/`+tag+`?`, ``, 1)
+		if len(uri) == 0 {
+			uri = `/` + uri
+		}
+		return uri, tag
+	}
+	return uri, tag + `?`
+}
+
+// format returns the shortest format which accepts n params, dropping the
+// segments of the trailing optional params.
+func (r *Route) format(n int) string {
+	if n >= len(r.Params) || !strings.HasSuffix(r.Path, `?`) {
+		return r.Format
+	}
+	format := r.Format
+	for i := len(r.Params); i > n; i-- {
+		pos := strings.LastIndex(format, `/%v`)
+		if pos < 0 || pos+3 != len(format) {
+			break
+		}
+		format = format[:pos]
+	}
+	if len(format) == 0 {
+		format = `/`
+	}
+	return format
+}
+
 func (r *Route) apply(e *Echo) *Route {
 	handler := e.ValidHandler(r.handler)
 	middleware := r.middleware
@@ -159,6 +349,12 @@ func (r *Route) apply(e *Echo) *Route {
 	} else {
 		r.Meta = H{}
 	}
+	if len(r.meta) > 0 {
+		r.Meta = r.Meta.Clone()
+		for key, value := range r.meta {
+			r.Meta[key] = value
+		}
+	}
 	for i := len(middleware) - 1; i >= 0; i-- {
 		m := middleware[i]
 		mw := e.ValidMiddleware(m)
@@ -168,27 +364,32 @@ func (r *Route) apply(e *Echo) *Route {
 	return r
 }
 
-func (m *methodHandler) addHandler(method string, h Handler, rid int) {
-	endpoint := &endpoint{handler: h, rid: rid}
+func (m *methodHandler) addHandler(method string, h Handler, rid int, priority int) {
+	ep := &endpoint{handler: h, rid: rid, priority: priority}
 	switch method {
 	case GET:
-		m.get = endpoint
+		m.get = ep
 	case POST:
-		m.post = endpoint
+		m.post = ep
 	case PUT:
-		m.put = endpoint
+		m.put = ep
 	case DELETE:
-		m.delete = endpoint
+		m.delete = ep
 	case PATCH:
-		m.patch = endpoint
+		m.patch = ep
 	case OPTIONS:
-		m.options = endpoint
+		m.options = ep
 	case HEAD:
-		m.head = endpoint
+		m.head = ep
 	case CONNECT:
-		m.connect = endpoint
+		m.connect = ep
 	case TRACE:
-		m.trace = endpoint
+		m.trace = ep
+	default:
+		if m.custom == nil {
+			m.custom = make(map[string]*endpoint)
+		}
+		m.custom[method] = ep
 	}
 }
 
@@ -221,12 +422,25 @@ func (m *methodHandler) find(method string) *endpoint {
 	case TRACE:
 		return m.trace
 	default:
-		return nil
+		return m.custom[method]
 	}
 }
 
+// methods returns the standard methods followed by the sorted custom methods.
+func (m *methodHandler) methods() []string {
+	if len(m.custom) == 0 {
+		return methods
+	}
+	custom := make([]string, 0, len(m.custom))
+	for method := range m.custom {
+		custom = append(custom, method)
+	}
+	sort.Strings(custom)
+	return append(append([]string{}, methods...), custom...)
+}
+
 func (m *methodHandler) check405() HandlerFunc {
-	for _, method := range methods {
+	for _, method := range m.methods() {
 		if r := m.findHandler(method); r != nil {
 			return MethodNotAllowedHandler
 		}
@@ -257,10 +471,31 @@ func NewRouter(e *Echo) *Router {
 }
 
 func (r *Router) Handle(c Context) Handler {
-	r.Find(c.Request().Method(), c.Request().URL().Path(), c)
+	path := c.Request().URL().Path()
+	if c.Object().withFormatExtension {
+		path = stripFormatExtension(c, path)
+	}
+	r.Find(c.Request().Method(), path, c)
 	return c
 }
 
+// stripFormatExtension removes the extension of a registered format renderer
+// from the last segment of the path, e.g. `/users/42.json`, and forces the
+// format. The request path is set to the base path.
+func stripFormatExtension(c Context, path string) string {
+	format, pos := formatExtension(path)
+	if pos < 0 {
+		return path
+	}
+	if _, ok := c.Echo().formatRenderers[format]; !ok {
+		return path
+	}
+	path = path[:pos]
+	c.Request().URL().SetPath(path)
+	c.SetFormat(format)
+	return path
+}
+
 // Add 添加路由
 // method: 方法(GET/POST/PUT/DELETE/PATCH/OPTIONS/HEAD/CONNECT/TRACE)
 // prefix: group前缀
@@ -268,21 +503,60 @@ func (r *Router) Handle(c Context) Handler {
 // h: Handler
 // name: Handler名
 // meta: meta数据
+// 尾部以`?`结尾的参数为可选参数，如`/posts/:year/:month?`
 func (r *Router) Add(rt *Route, rid int) {
-	path := rt.Path
-	ppath := path        // Pristine path
-	pnames := []string{} // Param names
+	if rt.Priority != 0 {
+		r.prioritized = true
+	}
+	paths := expandOptionalPath(rt.Path)
+	for _, path := range paths[1:] {
+		r.add(rt, path, rid)
+	}
+	for _, alias := range rt.Aliases {
+		for _, path := range expandOptionalPath(alias) {
+			r.add(rt, path, rid)
+		}
+	}
+	rt.Format, rt.Params = r.add(rt, paths[0], rid)
+	//Dump(rt)
+}
+
+// expandOptionalPath returns the full path followed by the shorter paths
+// without the trailing optional params.
+func expandOptionalPath(path string) []string {
+	if !strings.HasSuffix(path, `?`) {
+		return []string{path}
+	}
+	segments := strings.Split(path, `/`)
+	end := len(segments)
+	for end > 0 {
+		seg := segments[end-1]
+		if len(seg) < 2 || seg[0] != ':' || !strings.HasSuffix(seg, `?`) {
+			break
+		}
+		segments[end-1] = strings.TrimSuffix(seg, `?`)
+		end--
+	}
+	paths := []string{strings.Join(segments, `/`)}
+	for i := len(segments) - 1; i >= end; i-- {
+		short := strings.Join(segments[:i], `/`)
+		if len(short) == 0 {
+			short = `/`
+		}
+		paths = append(paths, short)
+	}
+	return paths
+}
+
+func (r *Router) add(rt *Route, path string, rid int) (format string, pnames []string) {
+	ppath := rt.Path    // Pristine path
+	pnames = []string{} // Param names
 	uri := new(bytes.Buffer)
-	defer func() {
-		rt.Format = uri.String()
-		rt.Params = pnames
-		//Dump(rt)
-	}()
 	for i, l := 0, len(path); i < l; i++ {
 		if path[i] == ':' {
 			uri.WriteString(`%v`)
 			j := i + 1
-			r.insert(rt.Method, path[:i], nil, skind, "", nil, -1)
+			r.insert(rt.Method, path[:i], nil, skind, "", nil, -1, 0)
 			for ; i < l && path[i] != '/'; i++ {
 			}
 
@@ -291,15 +565,15 @@ func (r *Router) Add(rt *Route, rid int) {
 			i, l = j, len(path)
 
 			if i == l {
-				r.insert(rt.Method, path[:i], rt.Handler, pkind, ppath, pnames, rid)
+				r.insert(rt.Method, path[:i], rt.Handler, pkind, ppath, pnames, rid, rt.Priority)
 			} else {
-				r.insert(rt.Method, path[:i], nil, pkind, "", nil, -1)
+				r.insert(rt.Method, path[:i], nil, pkind, "", nil, -1, 0)
 			}
 		} else if path[i] == '*' {
 			uri.WriteString(`%v`)
-			r.insert(rt.Method, path[:i], nil, skind, "", nil, -1)
+			r.insert(rt.Method, path[:i], nil, skind, "", nil, -1, 0)
 			pnames = append(pnames, "*")
-			r.insert(rt.Method, path[:i+1], rt.Handler, akind, ppath, pnames, rid)
+			r.insert(rt.Method, path[:i+1], rt.Handler, akind, ppath, pnames, rid, rt.Priority)
 			continue
 		}
 
@@ -310,17 +584,18 @@ func (r *Router) Add(rt *Route, rid int) {
 
 	//static route
 	if m, ok := r.static[path]; ok {
-		m.addHandler(rt.Method, rt.Handler, rid)
+		m.addHandler(rt.Method, rt.Handler, rid, rt.Priority)
 	} else {
 		m = &methodHandler{}
-		m.addHandler(rt.Method, rt.Handler, rid)
+		m.addHandler(rt.Method, rt.Handler, rid, rt.Priority)
 		r.static[path] = m
 	}
-	r.insert(rt.Method, path, rt.Handler, skind, ppath, pnames, rid)
+	r.insert(rt.Method, path, rt.Handler, skind, ppath, pnames, rid, rt.Priority)
+	format = uri.String()
 	return
 }
 
-func (r *Router) insert(method, path string, h Handler, t kind, ppath string, pnames []string, rid int) {
+func (r *Router) insert(method, path string, h Handler, t kind, ppath string, pnames []string, rid int, priority int) {
 	e := r.echo
 	// Adjust max param
 	l := len(pnames)
@@ -355,7 +630,7 @@ func (r *Router) insert(method, path string, h Handler, t kind, ppath string, pn
 			cn.prefix = search
 			if h != nil {
 				cn.kind = t
-				cn.addHandler(method, h, rid)
+				cn.addHandler(method, h, rid, priority)
 				cn.ppath = ppath
 				cn.pnames = pnames
 			}
@@ -377,13 +652,13 @@ func (r *Router) insert(method, path string, h Handler, t kind, ppath string, pn
 			if l == sl {
 				// At parent node
 				cn.kind = t
-				cn.addHandler(method, h, rid)
+				cn.addHandler(method, h, rid, priority)
 				cn.ppath = ppath
 				cn.pnames = pnames
 			} else {
 				// Create child node
 				n = newNode(t, search[l:], cn, nil, new(methodHandler), ppath, pnames)
-				n.addHandler(method, h, rid)
+				n.addHandler(method, h, rid, priority)
 				cn.addChild(n)
 			}
 		} else if l < sl {
@@ -396,12 +671,12 @@ func (r *Router) insert(method, path string, h Handler, t kind, ppath string, pn
 			}
 			// Create child node
 			n := newNode(t, search, cn, nil, new(methodHandler), ppath, pnames)
-			n.addHandler(method, h, rid)
+			n.addHandler(method, h, rid, priority)
 			cn.addChild(n)
 		} else {
 			// Node already exists
 			if h != nil {
-				cn.addHandler(method, h, rid)
+				cn.addHandler(method, h, rid, priority)
 				cn.ppath = ppath
 				if len(cn.pnames) == 0 {
 					cn.pnames = pnames
@@ -446,6 +721,47 @@ func (n *node) Tree() H {
 	}
 }
 
+// Tree returns a readable dump of the routing tree. Each line is a node prefix
+// indented by depth, followed by the handled path and its methods with route names.
+// Children are sorted by prefix, so the output is deterministic.
+func (r *Router) Tree() string {
+	buf := new(bytes.Buffer)
+	r.tree.dump(buf, r.routes, 0)
+	return buf.String()
+}
+
+func (n *node) dump(buf *bytes.Buffer, routes []*Route, depth int) {
+	buf.WriteString(strings.Repeat(`  `, depth))
+	buf.WriteString(n.prefix)
+	var handlers []string
+	for _, method := range n.methodHandler.methods() {
+		endpoint := n.find(method)
+		if endpoint == nil || endpoint.handler == nil {
+			continue
+		}
+		handler := method
+		if endpoint.rid >= 0 && endpoint.rid < len(routes) {
+			handler += ` ` + routes[endpoint.rid].Name
+		}
+		if endpoint.priority != 0 {
+			handler += fmt.Sprintf(` (priority %d)`, endpoint.priority)
+		}
+		handlers = append(handlers, handler)
+	}
+	if len(handlers) > 0 {
+		buf.WriteString(` -> ` + n.ppath + ` [` + strings.Join(handlers, `, `) + `]`)
+	}
+	buf.WriteByte('\n')
+	children := make([]*node, len(n.children))
+	copy(children, n.children)
+	sort.Slice(children, func(i, j int) bool {
+		return children[i].prefix < children[j].prefix
+	})
+	for _, child := range children {
+		child.dump(buf, routes, depth+1)
+	}
+}
+
 func (n *node) addChild(c *node) {
 	n.children = append(n.children, c)
 }
@@ -477,8 +793,8 @@ func (n *node) findChildByKind(t kind) *node {
 	return nil
 }
 
-func (n *node) addHandler(method string, h Handler, rid int) {
-	n.methodHandler.addHandler(method, h, rid)
+func (n *node) addHandler(method string, h Handler, rid int, priority int) {
+	n.methodHandler.addHandler(method, h, rid, priority)
 }
 
 func (n *node) findHandler(method string) Handler {
@@ -500,6 +816,102 @@ func (n *node) applyHandler(method string, ctx *xContext) {
 }
 
 func (r *Router) Find(method, path string, context Context) {
+	if r.prioritized {
+		r.findPrioritized(method, path, context)
+		return
+	}
+	r.find(method, path, context)
+}
+
+// routeMatch collects the routes matching a path in `Router.findPrioritized`.
+type routeMatch struct {
+	method   string
+	node     *node // node of the matched endpoint
+	endpoint *endpoint
+	pvalues  []string
+	fallback *node // first node matching the path, answers 405 if no endpoint matches
+	fvalues  []string
+}
+
+// findPrioritized walks all the routes matching the path and dispatches the
+// one with the highest priority, the static > param > any order breaks the ties.
+func (r *Router) findPrioritized(method, path string, context Context) {
+	ctx := context.Object()
+	ctx.path = path
+	pvalues := context.ParamValues()
+	m := &routeMatch{method: strings.ToUpper(method)}
+	m.match(r.tree, path, pvalues, 0)
+	cn, values := m.node, m.pvalues
+	if cn == nil {
+		if m.fallback == nil {
+			// Not found
+			return
+		}
+		cn, values = m.fallback, m.fvalues
+	}
+	copy(pvalues, values)
+	cn.applyHandler(m.method, ctx)
+	if ctx.handler == nil {
+		ctx.handler = cn.check405()
+	}
+}
+
+func (m *routeMatch) match(n *node, search string, pvalues []string, pn int) {
+	switch n.kind {
+	case pkind:
+		if pn >= len(pvalues) {
+			return
+		}
+		i, l := 0, len(search)
+		for ; i < l && search[i] != '/'; i++ {
+		}
+		pvalues[pn] = search[:i]
+		pn++
+		search = search[i:]
+	case akind:
+		pvalues[len(n.pnames)-1] = search
+		search = ""
+	default:
+		if !strings.HasPrefix(search, n.prefix) {
+			return
+		}
+		search = search[len(n.prefix):]
+	}
+	if search == "" {
+		m.add(n, pvalues)
+		// an empty value for *, e.g. serving a directory
+		if child := n.findChildByKind(akind); child != nil {
+			pvalues[len(child.pnames)-1] = ""
+			m.add(child, pvalues)
+		}
+		return
+	}
+	for _, t := range []kind{skind, pkind, akind} {
+		for _, c := range n.children {
+			if c.kind == t && (t != skind || c.label == search[0]) {
+				m.match(c, search, pvalues, pn)
+			}
+		}
+	}
+}
+
+func (m *routeMatch) add(n *node, pvalues []string) {
+	ep := n.find(m.method)
+	if ep == nil || ep.handler == nil {
+		if m.fallback == nil && len(n.ppath) > 0 {
+			m.fallback = n
+			m.fvalues = append([]string{}, pvalues...)
+		}
+		return
+	}
+	if m.endpoint == nil || ep.priority > m.endpoint.priority {
+		m.node, m.endpoint = n, ep
+		m.pvalues = append(m.pvalues[:0], pvalues...)
+	}
+}
+
+func (r *Router) find(method, path string, context Context) {
+	method = strings.ToUpper(method)
 	ctx := context.Object()
 	ctx.path = path
 	cn := r.tree // Current node as root
@@ -1,11 +1,13 @@
 package testing
 
 import (
+	"io"
 	"net/http"
 	"net/http/httptest"
 
 	"github.com/admpub/log"
 
+	"github.com/webx-top/echo"
 	"github.com/webx-top/echo/engine"
 	"github.com/webx-top/echo/engine/standard"
 )
@@ -44,3 +46,27 @@ func WrapRequest(req *http.Request) engine.Request {
 func WrapResponse(req *http.Request, rw http.ResponseWriter) engine.Response {
 	return standard.NewResponse(rw, req, log.New().Sync())
 }
+
+// ResponseRecorder records the response written through the context returned
+// by NewRequest and NewContext.
+type ResponseRecorder struct {
+	*httptest.ResponseRecorder
+}
+
+// NewRequest builds a context for the request against a new Echo instance,
+// the response written by the handler is recorded by the returned ResponseRecorder.
+//
+//	c, rec := testing.NewRequest(`POST`, `/users?page=1`, strings.NewReader(`name=foo`))
+//	c.Request().Header().Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+//	err := handler(c)
+func NewRequest(method, target string, body io.Reader) (echo.Context, *ResponseRecorder) {
+	return NewContext(echo.New(), method, target, body)
+}
+
+// NewContext is the same as NewRequest, but builds the context against e.
+func NewContext(e *echo.Echo, method, target string, body io.Reader) (echo.Context, *ResponseRecorder) {
+	req := httptest.NewRequest(method, target, body)
+	rec := &ResponseRecorder{ResponseRecorder: httptest.NewRecorder()}
+	c := e.NewContext(WrapRequest(req), WrapResponse(req, rec.ResponseRecorder))
+	return c, rec
+}
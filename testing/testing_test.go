@@ -0,0 +1,40 @@
+package testing_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/webx-top/echo"
+	test "github.com/webx-top/echo/testing"
+)
+
+func TestNewRequestGET(t *testing.T) {
+	c, rec := test.NewRequest(echo.GET, `/users?name=foo`, nil)
+	err := func(c echo.Context) error {
+		return c.String(`hello ` + c.Query(`name`))
+	}(c)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `hello foo`, rec.Body.String())
+}
+
+func TestNewRequestPOST(t *testing.T) {
+	e := echo.New()
+	c, rec := test.NewContext(e, echo.POST, `/users`, strings.NewReader(`{"name":"foo"}`))
+	c.Request().Header().Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	err := func(c echo.Context) error {
+		u := struct {
+			Name string `json:"name"`
+		}{}
+		if err := c.MustBind(&u); err != nil {
+			return err
+		}
+		return c.JSON(echo.H{`created`: u.Name}, http.StatusCreated)
+	}(c)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, `{"created":"foo"}`, strings.TrimSpace(rec.Body.String()))
+	assert.Equal(t, e, c.Echo())
+}
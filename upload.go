@@ -0,0 +1,73 @@
+package echo
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// UploadOptions restricts the file accepted by `Context.BindFile`.
+type UploadOptions struct {
+	// MaxSize is the maximum file size in bytes, 0 means unlimited.
+	MaxSize int64
+	// AllowedTypes lists the accepted MIME types, detected from the file content.
+	// `type/*` accepts every subtype of a type, empty accepts any type.
+	AllowedTypes []string
+}
+
+func (o *UploadOptions) allowType(contentType string) bool {
+	if len(o.AllowedTypes) == 0 {
+		return true
+	}
+	contentType = strings.ToLower(strings.TrimSpace(strings.SplitN(contentType, `;`, 2)[0]))
+	for _, typ := range o.AllowedTypes {
+		typ = strings.ToLower(typ)
+		if typ == contentType {
+			return true
+		}
+		if strings.HasSuffix(typ, `/*`) && strings.HasPrefix(contentType, typ[:len(typ)-1]) {
+			return true
+		}
+	}
+	return false
+}
+
+// SanitizeFileName returns the base name of the uploaded file name without any
+// directory, the characters other than letters, digits, `.`, `-` and `_` are
+// replaced by `_`.
+func SanitizeFileName(name string) string {
+	name = path.Base(strings.Replace(name, `\`, `/`, -1))
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			return r
+		}
+		return '_'
+	}, name)
+	name = strings.TrimLeft(name, `.`)
+	if len(name) == 0 {
+		return `file`
+	}
+	return name
+}
+
+// createUniqueFile creates the file named name in dir, the file is never
+// overwritten: if the name is taken, a counter is appended to the base name
+// (`a.txt`, `a_1.txt`, `a_2.txt`...) until a free name is found.
+func createUniqueFile(dir string, name string) (*os.File, error) {
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	for i := 0; ; i++ {
+		if i > 0 {
+			name = base + `_` + strconv.Itoa(i) + ext
+		}
+		f, err := os.OpenFile(filepath.Join(dir, name), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil || !os.IsExist(err) || i >= maxUniqueFileAttempts {
+			return f, err
+		}
+	}
+}
+
+const maxUniqueFileAttempts = 10000
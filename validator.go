@@ -20,6 +20,9 @@ package echo
 
 import (
 	"errors"
+	"reflect"
+	"sort"
+	"strings"
 
 	"github.com/webx-top/validation"
 )
@@ -142,3 +145,59 @@ func (v *Validation) Validate(i interface{}, args ...string) ValidateResult {
 	}
 	return e
 }
+
+// ValidateErrors is the structured validation failure returned by
+// `Context.BindAndValidate`. The default HTTP error handler responds it
+// as 422 with `{"errors":{"field":"message"}}`.
+type ValidateErrors struct {
+	Errors map[string]string `json:"errors"`
+}
+
+// NewValidateErrors collects the field errors of the result. The raw value may be
+// a slice of errors exposing the field by a `Field() string` method (e.g.
+// `validator.ValidationErrors`) or by a `Field` struct field.
+func NewValidateErrors(result ValidateResult) *ValidateErrors {
+	v := &ValidateErrors{Errors: map[string]string{}}
+	if raw := reflect.ValueOf(result.Raw()); raw.Kind() == reflect.Slice {
+		for i := 0; i < raw.Len(); i++ {
+			err, ok := raw.Index(i).Interface().(error)
+			if !ok {
+				continue
+			}
+			field := errorField(err)
+			if _, exists := v.Errors[field]; !exists {
+				v.Errors[field] = err.Error()
+			}
+		}
+	}
+	if len(v.Errors) == 0 && result.Error() != nil {
+		v.Errors[result.Field()] = result.Error().Error()
+	}
+	return v
+}
+
+func errorField(err error) string {
+	if f, ok := err.(interface{ Field() string }); ok {
+		return f.Field()
+	}
+	rv := reflect.Indirect(reflect.ValueOf(err))
+	if rv.Kind() == reflect.Struct {
+		if f := rv.FieldByName(`Field`); f.IsValid() && f.Kind() == reflect.String {
+			return f.String()
+		}
+	}
+	return ``
+}
+
+func (v *ValidateErrors) Error() string {
+	fields := make([]string, 0, len(v.Errors))
+	for field := range v.Errors {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	messages := make([]string, len(fields))
+	for i, field := range fields {
+		messages[i] = field + `: ` + v.Errors[field]
+	}
+	return strings.Join(messages, `; `)
+}
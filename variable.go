@@ -52,9 +52,12 @@ var (
 	ErrUnauthorized                error = NewHTTPError(http.StatusUnauthorized)
 	ErrForbidden                   error = NewHTTPError(http.StatusForbidden)
 	ErrStatusRequestEntityTooLarge error = NewHTTPError(http.StatusRequestEntityTooLarge)
+	ErrUploadTooLarge              error = NewHTTPError(http.StatusRequestEntityTooLarge, "uploaded file is too large")
 	ErrMethodNotAllowed            error = NewHTTPError(http.StatusMethodNotAllowed)
-	ErrRendererNotRegistered             = errors.New("renderer not registered")
+	ErrRendererNotRegistered       error = NewHTTPError(http.StatusInternalServerError, "renderer not registered, use Echo.SetRenderer to register one")
+	ErrNoResponse                  error = NewHTTPError(http.StatusInternalServerError, "the handler returned without writing a response")
 	ErrInvalidRedirectCode               = errors.New("invalid redirect status code")
+	ErrInvalidMiddleware                 = errors.New("invalid middleware")
 	ErrNotFoundFileInput                 = errors.New("The specified name file input was not found")
 
 	//----------------
@@ -19,9 +19,14 @@
 package echo
 
 import (
+	"compress/gzip"
+	"compress/zlib"
+	stdjson "encoding/json"
 	"encoding/xml"
 	"fmt"
+	"io"
 	"net/http"
+	"time"
 
 	"github.com/webx-top/echo/encoding/json"
 )
@@ -69,16 +74,10 @@ var (
 				return NewHTTPError(http.StatusBadRequest, "Request body can't be nil")
 			}
 			defer body.Close()
-			return json.NewDecoder(body).Decode(i)
-		},
-		MIMEApplicationXML: func(i interface{}, ctx Context, filter ...FormDataFilter) error {
-			body := ctx.Request().Body()
-			if body == nil {
-				return NewHTTPError(http.StatusBadRequest, "Request body can't be nil")
-			}
-			defer body.Close()
-			return xml.NewDecoder(body).Decode(i)
+			return bindJSON(i, body, ctx.Echo().JSONDecodeOptions())
 		},
+		MIMEApplicationXML: bindXML,
+		MIMETextXML:        bindXML,
 		MIMEApplicationForm: func(i interface{}, ctx Context, filter ...FormDataFilter) error {
 			return NamedStructMap(ctx.Echo(), i, ctx.Request().PostForm().All(), ``, filter...)
 		},
@@ -89,8 +88,68 @@ var (
 			return NamedStructMap(ctx.Echo(), i, ctx.Request().Form().All(), ``, filter...)
 		},
 	}
+	// DefaultSubRequestHeaders request headers copied to the requests of `Context.SubRequest`
+	DefaultSubRequestHeaders = []string{
+		HeaderAuthorization,
+		HeaderCookie,
+		`Accept-Language`,
+		`User-Agent`,
+		HeaderXForwardedFor,
+		HeaderXForwardedProto,
+		HeaderXRealIP,
+		HeaderXRequestID,
+	}
+	// DefaultRequestDecoders request body decoders (Content-Encoding=>decoder)
+	DefaultRequestDecoders = map[string]func(io.Reader) (io.Reader, error){
+		`gzip`: func(r io.Reader) (io.Reader, error) {
+			return gzip.NewReader(r)
+		},
+		`deflate`: func(r io.Reader) (io.Reader, error) {
+			return zlib.NewReader(r)
+		},
+	}
+	// DefaultHealthCheckTimeout overall timeout of the checks run by `Echo.HealthCheck`
+	DefaultHealthCheckTimeout = 5 * time.Second
+	// DefaultRequestBodyCacheSize maximum size of the body cached by
+	// `Context.RequestBody` when the engine does not limit the request body
+	// size, 0 for no limit
+	DefaultRequestBodyCacheSize int64 = 32 << 20 // 32 MB
+	// DefaultNDJSONMaxLineSize maximum size of a `Context.BindNDJSON` record
+	// when the engine does not limit the request body size
+	DefaultNDJSONMaxLineSize = 1 << 20 // 1 MB
 	// DefaultHTMLFilter html filter (`form_filter:"html"`)
 	DefaultHTMLFilter = func(v string) (r string) {
 		return v
 	}
 )
+
+// bindJSON decodes the JSON request body according to the options set by
+// `Echo.SetJSONDecodeOptions`. The options are only supported by the decoder
+// of `encoding/json`, which is used for them whatever the JSON build tag.
+func bindJSON(i interface{}, body io.Reader, options JSONDecodeOptions) error {
+	if !options.UseNumber && !options.DisallowUnknownFields {
+		return json.NewDecoder(body).Decode(i)
+	}
+	decoder := stdjson.NewDecoder(body)
+	if options.UseNumber {
+		decoder.UseNumber()
+	}
+	if options.DisallowUnknownFields {
+		decoder.DisallowUnknownFields()
+	}
+	return decoder.Decode(i)
+}
+
+// bindXML decodes the XML request body with `encoding/xml`, so `xml` struct
+// tags and namespaces are respected.
+func bindXML(i interface{}, ctx Context, filter ...FormDataFilter) error {
+	body := ctx.Request().Body()
+	if body == nil {
+		return NewHTTPError(http.StatusBadRequest, "Request body can't be nil")
+	}
+	defer body.Close()
+	if err := xml.NewDecoder(body).Decode(i); err != nil {
+		return NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	return nil
+}
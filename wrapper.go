@@ -21,6 +21,12 @@ package echo
 import (
 	"fmt"
 	"net/http"
+	"reflect"
+)
+
+var (
+	contextType = reflect.TypeOf((*Context)(nil)).Elem()
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
 )
 
 // WrapHandler wrap `interface{}` into `echo.Handler`.
@@ -63,8 +69,53 @@ func WrapHandler(h interface{}) Handler {
 	panic(fmt.Sprintf(`unknown handler: %T`, h))
 }
 
-// WrapMiddleware wrap `interface{}` into `echo.Middleware`.
+// WrapRPCHandler wrap `func(Context, *Req) (*Resp, error)` into `echo.Handler`.
+// The request is bound into a new `*Req` and the response is rendered as
+// JSON or XML according to the `Accept` header (204 No Content when nil).
+// It returns nil for other types, so it can be added via `Echo.AddHandlerWrapper`.
+func WrapRPCHandler(h interface{}) Handler {
+	fn := reflect.ValueOf(h)
+	t := fn.Type()
+	if t.Kind() != reflect.Func || t.NumIn() != 2 || t.NumOut() != 2 {
+		return nil
+	}
+	if t.In(0) != contextType || t.Out(1) != errorType {
+		return nil
+	}
+	in := t.In(1)
+	if in.Kind() != reflect.Ptr || in.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	return HandlerFunc(func(c Context) error {
+		req := reflect.New(in.Elem())
+		if err := c.Bind(req.Interface()); err != nil {
+			return err
+		}
+		results := fn.Call([]reflect.Value{reflect.ValueOf(c), req})
+		if err, _ := results[1].Interface().(error); err != nil {
+			return err
+		}
+		out := results[0]
+		switch out.Kind() {
+		case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice:
+			if out.IsNil() {
+				return c.NoContent(http.StatusNoContent)
+			}
+		}
+		if c.Accepts(`json`, `xml`) == `xml` {
+			return c.XML(out.Interface())
+		}
+		return c.JSON(out.Interface())
+	})
+}
+
+// WrapMiddleware wrap `interface{}` into `echo.Middleware`. It panics with an
+// error wrapping `ErrInvalidMiddleware` when m is nil or has none of the
+// supported shapes, so that invalid middleware is reported at registration.
 func WrapMiddleware(m interface{}) Middleware {
+	if m == nil || isNilFunc(m) {
+		panic(invalidMiddlewareError(m))
+	}
 	if h, ok := m.(MiddlewareFunc); ok {
 		return h
 	}
@@ -112,7 +163,16 @@ func WrapMiddleware(m interface{}) Middleware {
 	if v, ok := m.(func(http.ResponseWriter, *http.Request) error); ok {
 		return WrapMiddlewareFromStdHandleFuncd(v)
 	}
-	panic(fmt.Sprintf(`unknown middleware: %T`, m))
+	panic(invalidMiddlewareError(m))
+}
+
+func invalidMiddlewareError(m interface{}) error {
+	return fmt.Errorf(`%w: %#v (%T), expected an echo.Middleware, a func(echo.Handler) echo.Handler, a handler func or an http.Handler`, ErrInvalidMiddleware, m, m)
+}
+
+func isNilFunc(v interface{}) bool {
+	rv := reflect.ValueOf(v)
+	return rv.Kind() == reflect.Func && rv.IsNil()
 }
 
 // WrapMiddlewareFromHandler wrap `echo.HandlerFunc` into `echo.Middleware`.